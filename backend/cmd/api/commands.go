@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/learng/backend/internal/config"
+	"github.com/learng/backend/internal/services"
+)
+
+// runCommand runs a maintenance subcommand instead of the server
+func runCommand(args []string, cfg *config.Config, mediaService services.MediaService) error {
+	switch args[0] {
+	case "gc-media":
+		return runMediaGC(args[1:], cfg, mediaService)
+	default:
+		return fmt.Errorf("unknown command %q (available: gc-media)", args[0])
+	}
+}
+
+// runMediaGC handles `api gc-media [-dry-run] [-grace 24h]`
+func runMediaGC(args []string, cfg *config.Config, mediaService services.MediaService) error {
+	flags := flag.NewFlagSet("gc-media", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "list unreferenced media without deleting it")
+	grace := flags.Duration("grace", cfg.MediaGCGrace, "keep unreferenced media used within this")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := mediaService.CollectGarbage(*grace, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, item := range report.Items {
+		fmt.Fprintf(w, "%s\t%s\t%d bytes\n", item.Type, item.URL, item.Bytes)
+	}
+	w.Flush()
+
+	verb := "Removed"
+	if report.DryRun {
+		verb = "Would remove"
+	}
+	fmt.Printf("%s %d assets and %d files unused since %s, reclaiming %d bytes\n",
+		verb, report.Assets, report.Files, report.Cutoff.Format("2006-01-02 15:04:05"), report.ReclaimedBytes)
+	return nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 
 	"github.com/labstack/echo/v4"
@@ -11,11 +12,15 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/learng/backend/internal/config"
+	"github.com/learng/backend/internal/generation"
 	"github.com/learng/backend/internal/handlers"
+	"github.com/learng/backend/internal/mailer"
+	"github.com/learng/backend/internal/media"
 	customMiddleware "github.com/learng/backend/internal/middleware"
 	"github.com/learng/backend/internal/models"
 	"github.com/learng/backend/internal/repository"
 	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/storage"
 )
 
 func main() {
@@ -31,24 +36,96 @@ func main() {
 		log.Fatal("Failed to initialize database:", err)
 	}
 
+	// Initialize media storage
+	store, err := initStorage(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize storage:", err)
+	}
+	signer := initURLSigner(cfg, store)
+
+	// Initialize media generation providers
+	images, speech, err := initGeneration(cfg)
+	if err != nil {
+		log.Fatal("Failed to initialize media generation:", err)
+	}
+
 	// Initialize repositories
 	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	inviteRepo := repository.NewInviteRepository(db)
 	journeyRepo := repository.NewJourneyRepository(db)
 	scenarioRepo := repository.NewScenarioRepository(db)
 	wordRepo := repository.NewWordRepository(db)
+	progressRepo := repository.NewProgressRepository(db)
+	quizRepo := repository.NewQuizRepository(db)
+	trashRepo := repository.NewTrashRepository(db)
+	versionRepo := repository.NewVersionRepository(db)
+	mediaAssetRepo := repository.NewMediaAssetRepository(db)
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo, cfg.JWTSecret)
-	journeyService := services.NewJourneyService(journeyRepo, scenarioRepo)
+	inviteService := services.NewInviteService(inviteRepo)
+	authService := services.NewAuthService(
+		userRepo, sessionRepo, userTokenRepo, inviteService, initMailer(cfg),
+		cfg.JWTSecret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL, cfg.AppBaseURL,
+	)
+	journeyService := services.NewJourneyService(journeyRepo, scenarioRepo, wordRepo, quizRepo)
 	scenarioService := services.NewScenarioService(scenarioRepo, journeyRepo)
-	wordService := services.NewWordService(wordRepo, scenarioRepo)
+	wordService := services.NewWordService(wordRepo, scenarioRepo, journeyRepo, mediaAssetRepo)
+	versionService := services.NewVersionService(versionRepo, journeyRepo, scenarioRepo, wordRepo, quizRepo, progressRepo)
+	learnerService := services.NewLearnerService(journeyRepo, scenarioRepo, progressRepo, versionService, signer)
+	progressService := services.NewProgressService(progressRepo, wordRepo, journeyRepo, versionService, cfg.DailyReviewCap, signer)
+	quizService := services.NewQuizService(quizRepo, scenarioRepo, wordRepo, journeyRepo, versionService, progressService, signer)
+	trashService := services.NewTrashService(trashRepo, cfg.TrashRetention)
+	publishService := services.NewPublishService(journeyRepo, versionRepo, scenarioRepo, wordRepo, quizRepo)
+	mediaService := services.NewMediaService(mediaAssetRepo, versionRepo, store, cfg.MaxAudioDuration, initAudioDecoder(cfg))
+	bundleService := services.NewJourneyBundleService(
+		journeyRepo, scenarioRepo, wordRepo, quizRepo, mediaService,
+		store, cfg.MaxImageSize, cfg.MaxAudioSize, cfg.MaxImageDimension,
+	)
+	generationService := services.NewGenerationService(
+		wordRepo, scenarioRepo, journeyRepo, mediaService, images, speech, cfg.MaxImageDimension,
+	)
+
+	if err := authService.EnsureBootstrapAdmin(cfg.BootstrapAdminEmail, cfg.BootstrapAdminPassword); err != nil {
+		log.Fatal("Failed to create bootstrap admin:", err)
+	}
+	if err := versionService.EnsureLiveVersions(); err != nil {
+		log.Fatal("Failed to create journey versions:", err)
+	}
+	if err := mediaService.EnsureWordAssets(); err != nil {
+		log.Fatal("Failed to register media assets:", err)
+	}
+
+	// Maintenance subcommands, e.g. `api gc-media -dry-run`, run and exit
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:], cfg, mediaService); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Background jobs
+	trashService.StartPurgeJob(cfg.TrashPurgeInterval)
+	publishService.StartPublishJob(cfg.PublishCheckInterval)
+	mediaService.StartGCJob(cfg.MediaGCInterval, cfg.MediaGCGrace)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	journeyHandler := handlers.NewJourneyHandler(journeyService)
-	scenarioHandler := handlers.NewScenarioHandler(scenarioService)
-	wordHandler := handlers.NewWordHandler(wordService)
-	mediaHandler := handlers.NewMediaHandler(cfg.UploadDir)
+	scenarioHandler := handlers.NewScenarioHandler(scenarioService, journeyService, signer)
+	wordHandler := handlers.NewWordHandler(wordService, scenarioService, signer)
+	generationHandler := handlers.NewGenerationHandler(generationService, wordService, signer)
+	mediaHandler := handlers.NewMediaHandler(
+		mediaService, signer, cfg.MaxImageSize, cfg.MaxAudioSize, cfg.MaxImageDimension, cfg.MaxAudioDuration,
+	)
+	inviteHandler := handlers.NewInviteHandler(inviteService)
+	learnerHandler := handlers.NewLearnerHandler(learnerService, progressService)
+	quizHandler := handlers.NewQuizHandler(quizService, scenarioService)
+	bundleHandler := handlers.NewJourneyBundleHandler(bundleService, journeyService, cfg.MaxBundleSize)
+	trashHandler := handlers.NewTrashHandler(trashService)
+	publishHandler := handlers.NewPublishHandler(publishService, journeyService)
+	versionHandler := handlers.NewVersionHandler(versionService, journeyService, signer)
 
 	// Create Echo instance
 	e := echo.New()
@@ -72,37 +149,95 @@ func main() {
 	// Public routes (no authentication required)
 	api.POST("/auth/register", authHandler.Register)
 	api.POST("/auth/login", authHandler.Login)
+	api.POST("/auth/refresh", authHandler.Refresh)
+	api.POST("/auth/forgot-password", authHandler.ForgotPassword)
+	api.POST("/auth/reset-password", authHandler.ResetPassword)
+	api.POST("/auth/verify-email", authHandler.VerifyEmail)
 
 	// Protected routes (authentication required)
 	protected := api.Group("")
-	protected.Use(customMiddleware.AuthMiddleware(cfg.JWTSecret))
+	protected.Use(customMiddleware.AuthMiddleware(cfg.JWTSecret, authService))
 	protected.GET("/auth/me", authHandler.GetMe)
+	protected.POST("/auth/logout", authHandler.Logout)
+	protected.POST("/auth/logout-all", authHandler.LogoutAll)
+
+	// Content-mutating routes are restricted to admins; handlers additionally
+	// check that the admin owns the journey the content belongs to
+	adminOnly := customMiddleware.RequireRole("admin")
+
+	// Upload routes stop reading a body once it passes the route's file size
+	// limit, plus room for the multipart headers
+	uploadLimit := func(maxSize int64) echo.MiddlewareFunc {
+		return customMiddleware.BodyLimit(maxSize + multipartOverhead)
+	}
 
 	// Journey routes (admin only for create/update/delete)
 	protected.GET("/journeys", journeyHandler.GetJourneys)
 	protected.GET("/journeys/:id", journeyHandler.GetJourneyByID)
-	protected.POST("/journeys", journeyHandler.CreateJourney)
-	protected.PUT("/journeys/:id", journeyHandler.UpdateJourney)
-	protected.DELETE("/journeys/:id", journeyHandler.DeleteJourney)
+	protected.POST("/journeys", journeyHandler.CreateJourney, adminOnly)
+	protected.PUT("/journeys/:id", journeyHandler.UpdateJourney, adminOnly)
+	protected.DELETE("/journeys/:id", journeyHandler.DeleteJourney, adminOnly)
+	protected.GET("/journeys/:id/publish/check", publishHandler.ValidateJourney, adminOnly)
+	protected.POST("/journeys/:id/publish", publishHandler.PublishJourney, adminOnly)
+	protected.POST("/journeys/:id/unpublish", publishHandler.UnpublishJourney, adminOnly)
+	protected.POST("/journeys/:id/archive", publishHandler.ArchiveJourney, adminOnly)
+	protected.PUT("/journeys/:id/scenarios/order", scenarioHandler.ReorderScenarios, adminOnly)
+	protected.POST("/journeys/:id/restore", trashHandler.RestoreJourney, adminOnly)
+	protected.POST("/journeys/:id/clone", journeyHandler.CloneJourney, adminOnly)
+	protected.GET("/journeys/:id/versions", versionHandler.GetVersions, adminOnly)
+	protected.GET("/journeys/:id/versions/diff", versionHandler.DiffDraft, adminOnly)
+	protected.GET("/journeys/:id/versions/:versionId", versionHandler.GetVersion, adminOnly)
+	protected.POST("/journeys/:id/versions/:versionId/rollback", versionHandler.RollbackVersion, adminOnly)
+	protected.GET("/journeys/:id/export", bundleHandler.ExportJourney, adminOnly)
+	protected.POST("/journeys/import", bundleHandler.ImportJourney, adminOnly, uploadLimit(cfg.MaxBundleSize))
 
 	// Scenario routes
-	protected.POST("/scenarios", scenarioHandler.CreateScenario)
+	protected.POST("/scenarios", scenarioHandler.CreateScenario, adminOnly)
 	protected.GET("/scenarios/:id", scenarioHandler.GetScenarioByID)
-	protected.PUT("/scenarios/:id", scenarioHandler.UpdateScenario)
-	protected.DELETE("/scenarios/:id", scenarioHandler.DeleteScenario)
+	protected.PUT("/scenarios/:id", scenarioHandler.UpdateScenario, adminOnly)
+	protected.DELETE("/scenarios/:id", scenarioHandler.DeleteScenario, adminOnly)
+	protected.POST("/scenarios/:id/restore", trashHandler.RestoreScenario, adminOnly)
+	protected.POST("/scenarios/:id/quiz/generate", quizHandler.GenerateQuiz, adminOnly)
+	protected.POST("/scenarios/:id/words/import", wordHandler.ImportWords, adminOnly, uploadLimit(handlers.MaxImportSize))
+	protected.PUT("/scenarios/:id/words/order", wordHandler.ReorderWords, adminOnly)
 
 	// Word routes
-	protected.POST("/words", wordHandler.CreateWord)
+	protected.POST("/words", wordHandler.CreateWord, adminOnly)
 	protected.GET("/words/:id", wordHandler.GetWordByID)
-	protected.PUT("/words/:id", wordHandler.UpdateWord)
-	protected.DELETE("/words/:id", wordHandler.DeleteWord)
+	protected.PUT("/words/:id", wordHandler.UpdateWord, adminOnly)
+	protected.DELETE("/words/:id", wordHandler.DeleteWord, adminOnly)
+	protected.POST("/words/:id/restore", trashHandler.RestoreWord, adminOnly)
+	protected.POST("/words/:id/generate", generationHandler.GenerateWordMedia, adminOnly)
+
+	// Trash (soft-deleted content awaiting purge)
+	protected.GET("/trash", trashHandler.GetTrash, adminOnly)
 
-	// Media upload routes
-	protected.POST("/media/upload/image", mediaHandler.UploadImage)
-	protected.POST("/media/upload/audio", mediaHandler.UploadAudio)
+	// Quiz routes
+	protected.GET("/quizzes/:id", quizHandler.GetQuiz)
+	protected.POST("/quizzes/:id/submit", quizHandler.SubmitQuiz)
 
-	// Serve uploaded media files
-	e.Static("/uploads", cfg.UploadDir)
+	// Media routes
+	protected.GET("/media", mediaHandler.GetAssets, adminOnly)
+	protected.POST("/media/upload/image", mediaHandler.UploadImage, adminOnly, uploadLimit(cfg.MaxImageSize))
+	protected.POST("/media/upload/audio", mediaHandler.UploadAudio, adminOnly, uploadLimit(cfg.MaxAudioSize))
+
+	// Invite routes
+	protected.POST("/invites", inviteHandler.CreateInvite, adminOnly)
+	protected.GET("/invites", inviteHandler.GetInvites, adminOnly)
+	protected.DELETE("/invites/:id", inviteHandler.RevokeInvite, adminOnly)
+
+	// Learner routes (published content only)
+	learner := protected.Group("/learner")
+	learner.GET("/journeys", learnerHandler.GetJourneys)
+	learner.POST("/journeys/:id/upgrade", learnerHandler.UpgradeJourney)
+	learner.GET("/scenarios/:id", learnerHandler.GetScenarioByID)
+	learner.POST("/progress", learnerHandler.RecordProgress)
+	learner.GET("/review/due", learnerHandler.GetDueReviews)
+
+	// Serve uploaded media files from storage, to signed-in users or through
+	// signed URLs
+	mediaAuth := customMiddleware.MediaAuth(cfg.JWTSecret, authService, signer)
+	e.Match([]string{http.MethodGet, http.MethodHead}, "/uploads/*", mediaHandler.ServeFile, mediaAuth)
 
 	// Serve frontend static files (production only)
 	if cfg.StaticDir != "" {
@@ -123,15 +258,11 @@ func main() {
 	}
 }
 
-func initDatabase(cfg *config.Config) (*gorm.DB, error) {
-	// Ensure database directory exists
-	if err := os.MkdirAll(cfg.UploadDir+"/images", 0755); err != nil {
-		return nil, fmt.Errorf("failed to create images directory: %w", err)
-	}
-	if err := os.MkdirAll(cfg.UploadDir+"/audio", 0755); err != nil {
-		return nil, fmt.Errorf("failed to create audio directory: %w", err)
-	}
+// multipartOverhead is the room upload body limits leave for multipart
+// boundaries, headers and small form fields
+const multipartOverhead = 64 * 1024
 
+func initDatabase(cfg *config.Config) (*gorm.DB, error) {
 	// Open database connection
 	db, err := gorm.Open(sqlite.Open(cfg.DatabasePath), &gorm.Config{})
 	if err != nil {
@@ -142,6 +273,9 @@ func initDatabase(cfg *config.Config) (*gorm.DB, error) {
 	log.Println("Running database migrations...")
 	if err := db.AutoMigrate(
 		&models.User{},
+		&models.Session{},
+		&models.UserToken{},
+		&models.Invite{},
 		&models.Journey{},
 		&models.Scenario{},
 		&models.Word{},
@@ -149,6 +283,9 @@ func initDatabase(cfg *config.Config) (*gorm.DB, error) {
 		&models.QuizQuestion{},
 		&models.LearnerProgress{},
 		&models.QuizAttempt{},
+		&models.JourneyVersion{},
+		&models.LearnerJourneyPin{},
+		&models.MediaAsset{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
@@ -156,3 +293,65 @@ func initDatabase(cfg *config.Config) (*gorm.DB, error) {
 	log.Println("Database initialized successfully")
 	return db, nil
 }
+
+func initMailer(cfg *config.Config) mailer.Mailer {
+	if cfg.MailDriver == "smtp" {
+		log.Printf("Sending mail via SMTP server %s:%s", cfg.SMTPHost, cfg.SMTPPort)
+		return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	}
+	log.Println("Mail delivery disabled; logging outgoing mail")
+	return mailer.NewLogMailer(cfg.MailLogFile)
+}
+
+func initStorage(cfg *config.Config) (storage.Storage, error) {
+	if cfg.StorageDriver == "s3" {
+		log.Printf("Storing media in S3 bucket %s at %s", cfg.S3Bucket, cfg.S3Endpoint)
+		return storage.NewS3Storage(
+			cfg.S3Endpoint, cfg.S3Region, cfg.S3Bucket,
+			cfg.S3AccessKey, cfg.S3SecretKey, cfg.S3UseSSL, cfg.MediaBaseURL,
+		)
+	}
+	log.Println("Storing media in", cfg.UploadDir)
+	return storage.NewLocalStorage(cfg.UploadDir, cfg.MediaBaseURL)
+}
+
+// initURLSigner signs media URLs with MEDIA_SIGNING_KEY, or without one with
+// a key derived from JWT_SECRET
+func initURLSigner(cfg *config.Config, store storage.Storage) *storage.URLSigner {
+	key := cfg.MediaSigningKey
+	if key == "" {
+		log.Println("Warning: MEDIA_SIGNING_KEY not set; deriving the media URL signing key from JWT_SECRET")
+		key = storage.DeriveSigningKey(cfg.JWTSecret)
+	}
+	return storage.NewURLSigner(store, key, cfg.MediaURLTTL)
+}
+
+// initAudioDecoder finds ffmpeg. The server runs without it, accepting only
+// WAV audio.
+func initAudioDecoder(cfg *config.Config) *media.FFmpeg {
+	decoder, err := media.NewFFmpeg(cfg.FFmpegPath)
+	if err != nil {
+		log.Printf("Warning: ffmpeg not found (%v); MP3 and WebM audio will be rejected", err)
+		return nil
+	}
+	log.Println("Decoding audio with", decoder.Path())
+	return decoder
+}
+
+func initGeneration(cfg *config.Config) (generation.ImageGenerator, generation.SpeechSynthesizer, error) {
+	opts := generation.Options{
+		APIURL:  cfg.GenerationAPIURL,
+		APIKey:  cfg.GenerationAPIKey,
+		Timeout: cfg.GenerationTimeout,
+	}
+	images, err := generation.NewImageGenerator(cfg.ImageGenerator, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	speech, err := generation.NewSpeechSynthesizer(cfg.SpeechSynthesizer, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("Generating images with %s, speech with %s", cfg.ImageGenerator, cfg.SpeechSynthesizer)
+	return images, speech, nil
+}
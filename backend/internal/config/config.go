@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,10 +13,61 @@ type Config struct {
 	Port         string
 	DatabasePath string
 	JWTSecret    string
-	UploadDir    string
+	UploadDir    string // Local storage driver root
 	StaticDir    string // Frontend build directory (empty in dev)
 	MaxImageSize int64  // bytes
 	MaxAudioSize int64  // bytes
+
+	MaxImageDimension int // pixels, per side
+
+	MaxAudioDuration time.Duration // Longest audio clip accepted, after trimming silence
+	FFmpegPath       string        // Decodes MP3 and WebM audio; a path or a name on PATH
+
+	StorageDriver string // 'local' | 's3'
+	MediaBaseURL  string // URL prefix stored media is served from
+	S3Endpoint    string // host[:port], without scheme
+	S3Region      string
+	S3Bucket      string
+	S3AccessKey   string
+	S3SecretKey   string
+	S3UseSSL      bool
+
+	MediaSigningKey string        // HMAC key for signed media URLs (derived from JWTSecret if empty)
+	MediaURLTTL     time.Duration // Signed media URLs stay valid between this and twice this
+
+	MaxBundleSize int64 // bytes, journey import zip
+
+	DailyReviewCap int // max words a learner reviews per day
+
+	TrashRetention     time.Duration // How long deleted content stays restorable
+	TrashPurgeInterval time.Duration // How often expired trash is purged (0 disables)
+
+	PublishCheckInterval time.Duration // How often scheduled journeys are published (0 disables)
+
+	MediaGCInterval time.Duration // How often unreferenced media is collected (0 disables)
+	MediaGCGrace    time.Duration // How long unreferenced media must go unused before it is collected
+
+	ImageGenerator    string        // 'placeholder' | 'http'
+	SpeechSynthesizer string        // 'tone' | 'http'
+	GenerationAPIURL  string        // Base URL of the http provider
+	GenerationAPIKey  string        // Bearer token sent to the http provider
+	GenerationTimeout time.Duration // Per request to the http provider
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	AppBaseURL   string // Public frontend URL used in emailed links
+	MailDriver   string // 'log' | 'smtp'
+	MailLogFile  string // Log driver output file (empty logs to stdout)
+	MailFrom     string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	// First admin account, created on startup only when there are no users
+	BootstrapAdminEmail    string
+	BootstrapAdminPassword string
 }
 
 func Load() (*Config, error) {
@@ -30,12 +82,80 @@ func Load() (*Config, error) {
 		StaticDir:    getEnv("STATIC_DIR", ""),                   // Empty in dev, set in production
 		MaxImageSize: getEnvInt64("MAX_IMAGE_SIZE", 5*1024*1024), // 5MB default
 		MaxAudioSize: getEnvInt64("MAX_AUDIO_SIZE", 2*1024*1024), // 2MB default
+
+		MaxImageDimension: int(getEnvInt64("MAX_IMAGE_DIMENSION", 4096)),
+
+		MaxAudioDuration: getEnvDuration("MAX_AUDIO_DURATION", 30*time.Second),
+		FFmpegPath:       getEnv("FFMPEG_PATH", "ffmpeg"),
+
+		StorageDriver: getEnv("STORAGE_DRIVER", "local"),
+		MediaBaseURL:  getEnv("MEDIA_BASE_URL", "/uploads"),
+		S3Endpoint:    getEnv("S3_ENDPOINT", ""),
+		S3Region:      getEnv("S3_REGION", ""),
+		S3Bucket:      getEnv("S3_BUCKET", ""),
+		S3AccessKey:   getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:   getEnv("S3_SECRET_KEY", ""),
+		S3UseSSL:      getEnvBool("S3_USE_SSL", true),
+
+		MediaSigningKey: getEnv("MEDIA_SIGNING_KEY", ""),
+		MediaURLTTL:     getEnvDuration("MEDIA_URL_TTL", time.Hour),
+
+		MaxBundleSize: getEnvInt64("MAX_BUNDLE_SIZE", 100*1024*1024), // 100MB default
+
+		DailyReviewCap: int(getEnvInt64("DAILY_REVIEW_CAP", 50)),
+
+		TrashRetention:     getEnvDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeInterval: getEnvDuration("TRASH_PURGE_INTERVAL", time.Hour),
+
+		PublishCheckInterval: getEnvDuration("PUBLISH_CHECK_INTERVAL", time.Minute),
+
+		MediaGCInterval: getEnvDuration("MEDIA_GC_INTERVAL", 6*time.Hour),
+		MediaGCGrace:    getEnvDuration("MEDIA_GC_GRACE", 24*time.Hour),
+
+		ImageGenerator:    getEnv("IMAGE_GENERATOR", "placeholder"),
+		SpeechSynthesizer: getEnv("SPEECH_SYNTHESIZER", "tone"),
+		GenerationAPIURL:  getEnv("GENERATION_API_URL", ""),
+		GenerationAPIKey:  getEnv("GENERATION_API_KEY", ""),
+		GenerationTimeout: getEnvDuration("GENERATION_TIMEOUT", time.Minute),
+
+		AccessTokenTTL:  getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+
+		AppBaseURL:   getEnv("APP_BASE_URL", "http://localhost:5173"),
+		MailDriver:   getEnv("MAIL_DRIVER", "log"),
+		MailLogFile:  getEnv("MAIL_LOG_FILE", ""),
+		MailFrom:     getEnv("MAIL_FROM", "no-reply@learng.local"),
+		SMTPHost:     getEnv("SMTP_HOST", ""),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+
+		BootstrapAdminEmail:    getEnv("BOOTSTRAP_ADMIN_EMAIL", ""),
+		BootstrapAdminPassword: getEnv("BOOTSTRAP_ADMIN_PASSWORD", ""),
 	}
 
 	// Validate required fields
 	if cfg.JWTSecret == "" {
 		return nil, fmt.Errorf("JWT_SECRET environment variable is required")
 	}
+	if cfg.MediaURLTTL <= 0 {
+		return nil, fmt.Errorf("MEDIA_URL_TTL must be positive")
+	}
+	if cfg.StorageDriver != "local" && cfg.StorageDriver != "s3" {
+		return nil, fmt.Errorf("STORAGE_DRIVER must be 'local' or 's3'")
+	}
+	if cfg.StorageDriver == "s3" && (cfg.S3Endpoint == "" || cfg.S3Bucket == "") {
+		return nil, fmt.Errorf("S3_ENDPOINT and S3_BUCKET are required when STORAGE_DRIVER is 's3'")
+	}
+	if cfg.GenerationTimeout <= 0 {
+		return nil, fmt.Errorf("GENERATION_TIMEOUT must be positive")
+	}
+	if cfg.MailDriver != "log" && cfg.MailDriver != "smtp" {
+		return nil, fmt.Errorf("MAIL_DRIVER must be 'log' or 'smtp'")
+	}
+	if cfg.MailDriver == "smtp" && cfg.SMTPHost == "" {
+		return nil, fmt.Errorf("SMTP_HOST is required when MAIL_DRIVER is 'smtp'")
+	}
 
 	return cfg, nil
 }
@@ -55,3 +175,21 @@ func getEnvInt64(key string, fallback int64) int64 {
 	}
 	return fallback
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil {
+			return d
+		}
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
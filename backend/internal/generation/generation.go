@@ -0,0 +1,79 @@
+// Package generation produces word media with AI providers: a picture that
+// illustrates a word and a recording of it spoken. Providers are chosen by
+// name from a registry; the offline ones are deterministic, so the pipeline
+// can be developed and tested without network access.
+package generation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ImageRequest asks for a picture illustrating a word
+type ImageRequest struct {
+	Word     string `json:"word"`
+	Meaning  string `json:"meaning"`  // the word in the learner's language
+	Language string `json:"language"` // the word's language, e.g. "es"
+}
+
+// SpeechRequest asks for text spoken in a language
+type SpeechRequest struct {
+	Text     string `json:"text"`
+	Language string `json:"language"`
+}
+
+// ImageGenerator returns an image file, in any format media.Detect accepts
+type ImageGenerator interface {
+	GenerateImage(req ImageRequest) ([]byte, error)
+}
+
+// SpeechSynthesizer returns an audio file, in any format media.Detect accepts
+type SpeechSynthesizer interface {
+	Synthesize(req SpeechRequest) ([]byte, error)
+}
+
+// Options configures the providers that call a remote service
+type Options struct {
+	APIURL  string
+	APIKey  string
+	Timeout time.Duration
+}
+
+var imageGenerators = map[string]func(opts Options) (ImageGenerator, error){
+	"placeholder": func(Options) (ImageGenerator, error) { return NewPlaceholderImageGenerator(), nil },
+	"http":        func(opts Options) (ImageGenerator, error) { return NewHTTPProvider(opts) },
+}
+
+var speechSynthesizers = map[string]func(opts Options) (SpeechSynthesizer, error){
+	"tone": func(Options) (SpeechSynthesizer, error) { return NewToneSynthesizer(), nil },
+	"http": func(opts Options) (SpeechSynthesizer, error) { return NewHTTPProvider(opts) },
+}
+
+// NewImageGenerator returns the image generator registered under name
+func NewImageGenerator(name string, opts Options) (ImageGenerator, error) {
+	newGenerator, ok := imageGenerators[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown image generator %q (available: %s)", name, names(imageGenerators))
+	}
+	return newGenerator(opts)
+}
+
+// NewSpeechSynthesizer returns the speech synthesizer registered under name
+func NewSpeechSynthesizer(name string, opts Options) (SpeechSynthesizer, error) {
+	newSynthesizer, ok := speechSynthesizers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown speech synthesizer %q (available: %s)", name, names(speechSynthesizers))
+	}
+	return newSynthesizer(opts)
+}
+
+func names[T any](registry map[string]T) string {
+	list := make([]string, 0, len(registry))
+	for name := range registry {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
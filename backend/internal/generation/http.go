@@ -0,0 +1,81 @@
+package generation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxResponseSize bounds what is read back from a provider; anything larger
+// would fail media validation anyway
+const maxResponseSize = 20 * 1024 * 1024
+
+// HTTPProvider calls a generation service over HTTP. The request is POSTed as
+// JSON to {APIURL}/images or {APIURL}/speech, and the response body is the
+// media file itself. Pointed at a local mock server, it exercises the full
+// remote path offline.
+type HTTPProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewHTTPProvider(opts Options) (*HTTPProvider, error) {
+	if opts.APIURL == "" {
+		return nil, errors.New("GENERATION_API_URL is required for the http provider")
+	}
+	return &HTTPProvider{
+		baseURL: strings.TrimRight(opts.APIURL, "/"),
+		apiKey:  opts.APIKey,
+		client:  &http.Client{Timeout: opts.Timeout},
+	}, nil
+}
+
+func (p *HTTPProvider) GenerateImage(req ImageRequest) ([]byte, error) {
+	return p.post("/images", req)
+}
+
+func (p *HTTPProvider) Synthesize(req SpeechRequest) ([]byte, error) {
+	return p.post("/speech", req)
+}
+
+func (p *HTTPProvider) post(path string, payload any) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, p.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet := strings.TrimSpace(string(data[:min(len(data), 200)]))
+		return nil, fmt.Errorf("provider returned %s: %s", resp.Status, snippet)
+	}
+	if len(data) > maxResponseSize {
+		return nil, errors.New("provider response too large")
+	}
+	if len(data) == 0 {
+		return nil, errors.New("provider returned an empty response")
+	}
+	return data, nil
+}
@@ -0,0 +1,139 @@
+package generation
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Placeholder image layout, in pixels
+const (
+	placeholderWidth   = 640
+	placeholderHeight  = 480
+	placeholderMargin  = 40
+	wordFontSize       = 96 // largest; shrunk until the word fits
+	minWordFontSize    = 24
+	meaningFontSize    = 36
+	placeholderLineGap = 24
+)
+
+// PlaceholderImageGenerator renders the word and its meaning on a background
+// colour derived from the word, as a PNG. The same request always gives the
+// same image. Characters the Go fonts lack, such as CJK, render as boxes.
+type PlaceholderImageGenerator struct {
+	bold    *opentype.Font
+	regular *opentype.Font
+}
+
+func NewPlaceholderImageGenerator() *PlaceholderImageGenerator {
+	// The embedded fonts are known to parse
+	bold, _ := opentype.Parse(gobold.TTF)
+	regular, _ := opentype.Parse(goregular.TTF)
+	return &PlaceholderImageGenerator{bold: bold, regular: regular}
+}
+
+func (g *PlaceholderImageGenerator) GenerateImage(req ImageRequest) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, placeholderWidth, placeholderHeight))
+	background, foreground := placeholderColors(req.Word)
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+
+	wordFace, err := g.fit(g.bold, req.Word, wordFontSize)
+	if err != nil {
+		return nil, err
+	}
+	defer wordFace.Close()
+	meaningFace, err := g.fit(g.regular, req.Meaning, meaningFontSize)
+	if err != nil {
+		return nil, err
+	}
+	defer meaningFace.Close()
+
+	// Centre the word, or the word above its meaning
+	wordHeight := wordFace.Metrics().Ascent.Ceil()
+	blockHeight := wordHeight
+	if req.Meaning != "" {
+		blockHeight += placeholderLineGap + meaningFace.Metrics().Ascent.Ceil()
+	}
+	baseline := (placeholderHeight-blockHeight)/2 + wordHeight
+	drawCentered(img, wordFace, foreground, req.Word, baseline)
+	if req.Meaning != "" {
+		baseline += placeholderLineGap + meaningFace.Metrics().Ascent.Ceil()
+		drawCentered(img, meaningFace, foreground, req.Meaning, baseline)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fit returns the largest face, up to size, in which text fits between the
+// margins
+func (g *PlaceholderImageGenerator) fit(f *opentype.Font, text string, size float64) (font.Face, error) {
+	for {
+		face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: size, DPI: 72, Hinting: font.HintingFull})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load font: %w", err)
+		}
+		if size <= minWordFontSize || font.MeasureString(face, text).Ceil() <= placeholderWidth-2*placeholderMargin {
+			return face, nil
+		}
+		face.Close()
+		size = math.Max(minWordFontSize, size*0.9)
+	}
+}
+
+func drawCentered(img draw.Image, face font.Face, c color.Color, text string, baseline int) {
+	d := &font.Drawer{Dst: img, Src: image.NewUniform(c), Face: face}
+	width := d.MeasureString(text)
+	d.Dot = fixed.Point26_6{X: (fixed.I(placeholderWidth) - width) / 2, Y: fixed.I(baseline)}
+	d.DrawString(text)
+}
+
+// placeholderColors picks a pastel background from a hash of the word, with
+// dark text on it
+func placeholderColors(word string) (color.Color, color.Color) {
+	h := fnv.New32a()
+	h.Write([]byte(word))
+	hue := float64(h.Sum32()%360) / 360
+
+	r, g, b := hslToRGB(hue, 0.55, 0.82)
+	fr, fg, fb := hslToRGB(hue, 0.45, 0.22)
+	return color.RGBA{r, g, b, 0xff}, color.RGBA{fr, fg, fb, 0xff}
+}
+
+func hslToRGB(h, s, l float64) (uint8, uint8, uint8) {
+	q := l + s - l*s
+	if l < 0.5 {
+		q = l * (1 + s)
+	}
+	p := 2*l - q
+	channel := func(t float64) uint8 {
+		t -= math.Floor(t)
+		var v float64
+		switch {
+		case t < 1.0/6:
+			v = p + (q-p)*6*t
+		case t < 1.0/2:
+			v = q
+		case t < 2.0/3:
+			v = p + (q-p)*(2.0/3-t)*6
+		default:
+			v = p
+		}
+		return uint8(math.Round(v * 255))
+	}
+	return channel(h + 1.0/3), channel(h), channel(h - 1.0/3)
+}
@@ -54,6 +54,107 @@ func (h *AuthHandler) Login(c echo.Context) error {
 	return c.JSON(http.StatusOK, response)
 }
 
+// Refresh exchanges a refresh token for a new token pair
+// POST /api/v1/auth/refresh
+func (h *AuthHandler) Refresh(c echo.Context) error {
+	var req services.RefreshRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	response, err := h.authService.Refresh(req)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, utils.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// Logout revokes the current session
+// POST /api/v1/auth/logout
+func (h *AuthHandler) Logout(c echo.Context) error {
+	sessionID, _ := c.Get("sessionId").(string)
+
+	if err := h.authService.Logout(sessionID); err != nil {
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to log out"))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// LogoutAll revokes every session of the current user
+// POST /api/v1/auth/logout-all
+func (h *AuthHandler) LogoutAll(c echo.Context) error {
+	userID, err := utils.GetUserID(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, utils.ErrorResponse(err.Error()))
+	}
+
+	if err := h.authService.LogoutAll(userID); err != nil {
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to log out"))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ForgotPassword sends a password reset email
+// POST /api/v1/auth/forgot-password
+func (h *AuthHandler) ForgotPassword(c echo.Context) error {
+	var req services.ForgotPasswordRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	if err := h.authService.ForgotPassword(req); err != nil {
+		if err.Error() == "invalid email format" {
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to send password reset email"))
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "If an account exists for that email, a password reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password from a reset token
+// POST /api/v1/auth/reset-password
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var req services.ResetPasswordRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	if err := h.authService.ResetPassword(req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Password has been reset",
+	})
+}
+
+// VerifyEmail confirms a user's email address
+// POST /api/v1/auth/verify-email
+func (h *AuthHandler) VerifyEmail(c echo.Context) error {
+	var req services.VerifyEmailRequest
+
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	if err := h.authService.VerifyEmail(req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "Email has been verified",
+	})
+}
+
 // GetMe returns the currently authenticated user
 // GET /api/v1/auth/me
 func (h *AuthHandler) GetMe(c echo.Context) error {
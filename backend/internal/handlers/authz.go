@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/utils"
+)
+
+// forbidden writes the standard 403 response for a failed ownership check,
+// e.g. forbidden(c, "update", "scenario")
+func forbidden(c echo.Context, action, resource string) error {
+	return c.JSON(http.StatusForbidden, utils.ErrorResponse(
+		fmt.Sprintf("You don't have permission to %s this %s", action, resource),
+	))
+}
+
+// isNotFound reports whether a service error means a resource in the
+// journey → scenario → word chain does not exist
+func isNotFound(err error) bool {
+	switch err.Error() {
+	case "journey not found", "scenario not found", "word not found", "record not found":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/storage"
+	"github.com/learng/backend/internal/utils"
+)
+
+type GenerationHandler struct {
+	generationService services.GenerationService
+	wordService       services.WordService
+	signer            *storage.URLSigner
+}
+
+func NewGenerationHandler(generationService services.GenerationService, wordService services.WordService, signer *storage.URLSigner) *GenerationHandler {
+	return &GenerationHandler{
+		generationService: generationService,
+		wordService:       wordService,
+		signer:            signer,
+	}
+}
+
+// GenerateWordMedia handles POST /api/v1/words/:id/generate
+func (h *GenerationHandler) GenerateWordMedia(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	ownerID, err := h.wordService.GetOwnerID(id)
+	if err != nil {
+		if isNotFound(err) {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Word not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch word"))
+	}
+	if ownerID != userID {
+		return forbidden(c, "update", "word")
+	}
+
+	var req services.GenerateMediaRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	word, err := h.generationService.GenerateWordMedia(userID, id, req)
+	if err != nil {
+		switch {
+		case isNotFound(err):
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Word not found"))
+		case err.Error() == "image or audio must be requested":
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		case errors.Is(err, services.ErrGenerationFailed):
+			return c.JSON(http.StatusBadGateway, utils.ErrorResponse(err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to generate media"))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(signWord(h.signer, word)))
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/utils"
+)
+
+type InviteHandler struct {
+	inviteService services.InviteService
+}
+
+func NewInviteHandler(inviteService services.InviteService) *InviteHandler {
+	return &InviteHandler{inviteService: inviteService}
+}
+
+// CreateInvite handles POST /api/v1/invites
+func (h *InviteHandler) CreateInvite(c echo.Context) error {
+	userID := c.Get("userId").(string)
+
+	var req services.CreateInviteRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	invite, err := h.inviteService.CreateInvite(userID, req)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusCreated, utils.SuccessResponse(invite))
+}
+
+// GetInvites handles GET /api/v1/invites
+func (h *InviteHandler) GetInvites(c echo.Context) error {
+	invites, err := h.inviteService.GetInvites()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch invites"))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(invites))
+}
+
+// RevokeInvite handles DELETE /api/v1/invites/:id
+func (h *InviteHandler) RevokeInvite(c echo.Context) error {
+	id := c.Param("id")
+
+	if err := h.inviteService.RevokeInvite(id); err != nil {
+		if err.Error() == "invite not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Invite not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to revoke invite"))
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
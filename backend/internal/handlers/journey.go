@@ -108,18 +108,19 @@ func (h *JourneyHandler) GetJourneyByID(c echo.Context) error {
 	}
 
 	response := map[string]interface{}{
-		"id":             journey.ID,
-		"title":          journey.Title,
-		"description":    journey.Description,
-		"sourceLanguage": journey.SourceLanguage,
-		"targetLanguage": journey.TargetLanguage,
-		"status":         journey.Status,
-		"createdBy":      journey.CreatedBy,
-		"createdAt":      journey.CreatedAt,
-		"updatedAt":      journey.UpdatedAt,
-		"scenarios":      journey.Scenarios,
-		"scenarioCount":  scenarioCount,
-		"wordCount":      wordCount,
+		"id":              journey.ID,
+		"title":           journey.Title,
+		"description":     journey.Description,
+		"sourceLanguage":  journey.SourceLanguage,
+		"targetLanguage":  journey.TargetLanguage,
+		"status":          journey.Status,
+		"createdBy":       journey.CreatedBy,
+		"sourceJourneyId": journey.SourceJourneyID,
+		"createdAt":       journey.CreatedAt,
+		"updatedAt":       journey.UpdatedAt,
+		"scenarios":       journey.Scenarios,
+		"scenarioCount":   scenarioCount,
+		"wordCount":       wordCount,
 	}
 
 	return c.JSON(http.StatusOK, utils.SuccessResponse(response))
@@ -137,7 +138,7 @@ func (h *JourneyHandler) UpdateJourney(c echo.Context) error {
 	}
 
 	if journey.CreatedBy != userID {
-		return c.JSON(http.StatusForbidden, utils.ErrorResponse("You don't have permission to update this journey"))
+		return forbidden(c, "update", "journey")
 	}
 
 	var updates map[string]interface{}
@@ -165,7 +166,7 @@ func (h *JourneyHandler) DeleteJourney(c echo.Context) error {
 	}
 
 	if journey.CreatedBy != userID {
-		return c.JSON(http.StatusForbidden, utils.ErrorResponse("You don't have permission to delete this journey"))
+		return forbidden(c, "delete", "journey")
 	}
 
 	if err := h.journeyService.DeleteJourney(id); err != nil {
@@ -174,3 +175,24 @@ func (h *JourneyHandler) DeleteJourney(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// CloneJourney handles POST /api/v1/journeys/:id/clone
+func (h *JourneyHandler) CloneJourney(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	var req services.CloneJourneyRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	clone, err := h.journeyService.CloneJourney(id, userID, req)
+	if err != nil {
+		if err.Error() == "journey not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to clone journey"))
+	}
+
+	return c.JSON(http.StatusCreated, utils.SuccessResponse(clone))
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/utils"
+)
+
+type JourneyBundleHandler struct {
+	bundleService  services.JourneyBundleService
+	journeyService services.JourneyService
+	maxBundleSize  int64
+}
+
+func NewJourneyBundleHandler(
+	bundleService services.JourneyBundleService,
+	journeyService services.JourneyService,
+	maxBundleSize int64,
+) *JourneyBundleHandler {
+	return &JourneyBundleHandler{
+		bundleService:  bundleService,
+		journeyService: journeyService,
+		maxBundleSize:  maxBundleSize,
+	}
+}
+
+var unsafeFilenameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// ExportJourney handles GET /api/v1/journeys/:id/export
+func (h *JourneyBundleHandler) ExportJourney(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	journey, err := h.journeyService.GetJourneyByID(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+	}
+	if journey.CreatedBy != userID {
+		return forbidden(c, "export", "journey")
+	}
+
+	bundle, err := h.bundleService.ExportJourney(id)
+	if err != nil {
+		if err.Error() == "journey not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to export journey"))
+	}
+
+	name := strings.Trim(unsafeFilenameChars.ReplaceAllString(strings.ToLower(bundle.Journey.Title), "-"), "-")
+	if name == "" {
+		name = "journey"
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.zip"`, name))
+	c.Response().WriteHeader(http.StatusOK)
+
+	// Headers are already sent, so a failure here can only be logged
+	if err := h.bundleService.WriteBundle(c.Response(), bundle); err != nil {
+		c.Logger().Errorf("export journey %s: %v", id, err)
+	}
+	return nil
+}
+
+// ImportJourney handles POST /api/v1/journeys/import
+func (h *JourneyBundleHandler) ImportJourney(c echo.Context) error {
+	userID := c.Get("userId").(string)
+
+	// Bundles need random access, so the multipart reader spools large ones to
+	// a temporary file, which is removed when the request ends
+	tooLarge := fmt.Sprintf("File too large (max %s)", utils.FormatSize(h.maxBundleSize))
+	file, err := c.FormFile("file")
+	if err != nil {
+		if errors.Is(bodyReadError(err), errFileTooLarge) {
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(tooLarge))
+		}
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("No file uploaded"))
+	}
+	if file.Size > h.maxBundleSize {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(tooLarge))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to read file"))
+	}
+	defer src.Close()
+
+	result, err := h.bundleService.ImportJourney(userID, src, file.Size)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidBundle) {
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to import journey"))
+	}
+
+	return c.JSON(http.StatusCreated, utils.SuccessResponse(result))
+}
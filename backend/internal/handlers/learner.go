@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/utils"
+)
+
+type LearnerHandler struct {
+	learnerService  services.LearnerService
+	progressService services.ProgressService
+}
+
+func NewLearnerHandler(learnerService services.LearnerService, progressService services.ProgressService) *LearnerHandler {
+	return &LearnerHandler{
+		learnerService:  learnerService,
+		progressService: progressService,
+	}
+}
+
+// GetJourneys handles GET /api/v1/learner/journeys
+func (h *LearnerHandler) GetJourneys(c echo.Context) error {
+	userID := c.Get("userId").(string)
+
+	page := 1
+	limit := 20
+
+	if pageStr := c.QueryParam("page"); pageStr != "" {
+		if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
+			page = p
+		}
+	}
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	journeys, total, err := h.learnerService.GetPublishedJourneys(userID, page, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch journeys"))
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"journeys": journeys,
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+	})
+}
+
+// GetScenarioByID handles GET /api/v1/learner/scenarios/:id
+func (h *LearnerHandler) GetScenarioByID(c echo.Context) error {
+	userID := c.Get("userId").(string)
+	id := c.Param("id")
+
+	scenario, err := h.learnerService.GetPublishedScenario(userID, id)
+	if err != nil {
+		if err.Error() == "scenario not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Scenario not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch scenario"))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(scenario))
+}
+
+// UpgradeJourney handles POST /api/v1/learner/journeys/:id/upgrade
+// Moves the learner onto the journey's live version before they finish theirs.
+func (h *LearnerHandler) UpgradeJourney(c echo.Context) error {
+	userID := c.Get("userId").(string)
+	id := c.Param("id")
+
+	journey, err := h.learnerService.UpgradeJourney(userID, id)
+	if err != nil {
+		if err.Error() == "journey not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to upgrade journey"))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(journey))
+}
+
+// RecordProgress handles POST /api/v1/learner/progress
+func (h *LearnerHandler) RecordProgress(c echo.Context) error {
+	userID := c.Get("userId").(string)
+
+	var req services.RecordViewRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	progress, err := h.progressService.RecordView(userID, req)
+	if err != nil {
+		if err.Error() == "word not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Word not found"))
+		}
+		if err.Error() == "word ID is required" {
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to record progress"))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(progress))
+}
+
+// GetDueReviews handles GET /api/v1/learner/review/due
+func (h *LearnerHandler) GetDueReviews(c echo.Context) error {
+	userID := c.Get("userId").(string)
+
+	limit := 0
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	reviews, err := h.progressService.GetDueReviews(userID, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch due reviews"))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(reviews))
+}
@@ -1,216 +1,257 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
+	"path"
 	"strings"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/media"
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/storage"
 	"github.com/learng/backend/internal/utils"
 )
 
 type MediaHandler struct {
-	uploadDir    string
-	maxImageSize int64
-	maxAudioSize int64
+	mediaService      services.MediaService
+	signer            *storage.URLSigner
+	maxImageSize      int64
+	maxAudioSize      int64
+	maxImageDimension int
+	maxAudioDuration  time.Duration
 }
 
-func NewMediaHandler(uploadDir string) *MediaHandler {
+func NewMediaHandler(
+	mediaService services.MediaService,
+	signer *storage.URLSigner,
+	maxImageSize, maxAudioSize int64,
+	maxImageDimension int,
+	maxAudioDuration time.Duration,
+) *MediaHandler {
 	return &MediaHandler{
-		uploadDir:    uploadDir,
-		maxImageSize: 5 * 1024 * 1024, // 5MB
-		maxAudioSize: 2 * 1024 * 1024, // 2MB
+		mediaService:      mediaService,
+		signer:            signer,
+		maxImageSize:      maxImageSize,
+		maxAudioSize:      maxAudioSize,
+		maxImageDimension: maxImageDimension,
+		maxAudioDuration:  maxAudioDuration,
 	}
 }
 
-// UploadImage handles image file uploads
-func (h *MediaHandler) UploadImage(c echo.Context) error {
-	file, err := c.FormFile("file")
+// GetAssets handles GET /api/v1/media
+func (h *MediaHandler) GetAssets(c echo.Context) error {
+	assets, err := h.mediaService.ListAssets(c.QueryParam("kind"))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("No file uploaded"))
-	}
-
-	// Validate file size
-	if file.Size > h.maxImageSize {
-		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(
-			fmt.Sprintf("File too large (max %dMB)", h.maxImageSize/(1024*1024)),
-		))
+		if strings.HasPrefix(err.Error(), "kind must be") {
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch media"))
 	}
 
-	// Validate file extension
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if !isValidImageExtension(ext) {
-		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(
-			"Invalid file extension. Supported: .jpg, .jpeg, .png, .webp",
-		))
+	for i := range assets {
+		assets[i].ImageSet = assets[i].ImageSet.Map(h.signer.Sign)
+		signAsset(h.signer, &assets[i].MediaAsset)
 	}
+	return c.JSON(http.StatusOK, utils.SuccessResponse(assets))
+}
 
-	// Validate MIME type
-	mimeType := file.Header.Get("Content-Type")
-	if !isValidImageType(mimeType) {
-		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(
-			"Invalid file type. Supported formats: JPEG, PNG, WebP",
-		))
+// ServeFile handles GET /uploads/*. Range requests and conditional requests
+// on the ETag are answered from storage. A stored object never changes, so
+// responses may be cached for a year, or until a signed URL expires.
+func (h *MediaHandler) ServeFile(c echo.Context) error {
+	rs, info, err := h.mediaService.Open(c.Param("*"))
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			return echo.ErrNotFound
+		}
+		return echo.ErrInternalServerError
 	}
+	defer rs.Close()
 
-	// Generate unique filename
-	filename := uuid.New().String() + ext
-	imageDir := filepath.Join(h.uploadDir, "images")
-
-	// Ensure directory exists
-	if err := os.MkdirAll(imageDir, 0755); err != nil {
-		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to create upload directory"))
+	maxAge := mediaMaxAge
+	if expiresAt, ok := c.Get("mediaExpiresAt").(time.Time); ok {
+		maxAge = min(maxAge, time.Until(expiresAt))
 	}
 
-	savePath := filepath.Join(imageDir, filename)
-
-	// Save file
-	if err := saveUploadedFile(file, savePath); err != nil {
-		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to save file"))
-	}
+	header := c.Response().Header()
+	header.Set(echo.HeaderContentType, info.ContentType)
+	header.Set("ETag", info.ETag)
+	header.Set("Cache-Control", fmt.Sprintf("private, max-age=%d, immutable", int(maxAge.Seconds())))
+	header.Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Response(), c.Request(), path.Base(info.Key), info.ModTime, rs)
+	return nil
+}
 
-	// Return response
-	url := "/uploads/images/" + filename
-	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"url":      url,
-		"filename": filename,
-		"size":     file.Size,
-		"mimeType": mimeType,
-	})
+// UploadImage handles image file uploads
+func (h *MediaHandler) UploadImage(c echo.Context) error {
+	return h.upload(c, media.KindImage, h.maxImageSize, "Invalid file type. Supported formats: JPEG, PNG, WebP")
 }
 
 // UploadAudio handles audio file uploads
 func (h *MediaHandler) UploadAudio(c echo.Context) error {
-	file, err := c.FormFile("file")
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("No file uploaded"))
-	}
-
-	// Validate file size
-	if file.Size > h.maxAudioSize {
-		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(
-			fmt.Sprintf("File too large (max %dMB)", h.maxAudioSize/(1024*1024)),
-		))
-	}
+	return h.upload(c, media.KindAudio, h.maxAudioSize, "Invalid file type. Supported formats: MP3, WAV, WebM")
+}
 
-	// Validate file extension (primary validation)
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if !isValidAudioExtension(ext) {
-		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(
-			"Invalid file extension. Supported: .mp3, .wav, .webm",
-		))
-	}
+// upload stores a file of the given kind. The format is detected from the
+// file's content; the client's filename and Content-Type are ignored. A file
+// identical to an earlier upload is not stored again: the existing asset is
+// returned with 200 instead of 201.
+func (h *MediaHandler) upload(c echo.Context, kind string, maxSize int64, invalidTypeMsg string) error {
+	userID := c.Get("userId").(string)
 
-	// Validate MIME type (allow application/octet-stream if extension is valid)
-	mimeType := file.Header.Get("Content-Type")
-	if !isValidAudioType(mimeType) {
-		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(
-			"Invalid file type. Supported formats: MP3, WAV, WebM",
-		))
+	data, err := readUploadedFile(c, maxSize)
+	if err != nil {
+		switch {
+		case errors.Is(err, errNoFile):
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse("No file uploaded"))
+		case errors.Is(err, errFileTooLarge):
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(
+				fmt.Sprintf("File too large (max %s)", utils.FormatSize(maxSize)),
+			))
+		}
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to read file"))
 	}
 
-	// Generate unique filename
-	filename := uuid.New().String() + ext
-	audioDir := filepath.Join(h.uploadDir, "audio")
-
-	// Ensure directory exists
-	if err := os.MkdirAll(audioDir, 0755); err != nil {
-		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to create upload directory"))
+	// Validate content
+	info, err := media.Validate(data, kind, h.maxImageDimension)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrUnsupported), errors.Is(err, media.ErrWrongKind):
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(invalidTypeMsg))
+		case errors.Is(err, media.ErrTooLarge):
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(
+				fmt.Sprintf("Image too large (max %dx%d pixels)", h.maxImageDimension, h.maxImageDimension),
+			))
+		case errors.Is(err, media.ErrTruncated), errors.Is(err, media.ErrMalformed):
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse("File is incomplete or corrupted"))
+		default:
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse("File contains unexpected extra content"))
+		}
 	}
 
-	savePath := filepath.Join(audioDir, filename)
-
-	// Save file
-	if err := saveUploadedFile(file, savePath); err != nil {
+	asset, created, err := h.mediaService.Save(userID, data, info)
+	if err != nil {
+		switch {
+		case errors.Is(err, media.ErrMalformed):
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse("File is incomplete or corrupted"))
+		case errors.Is(err, media.ErrTooLong):
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(
+				fmt.Sprintf("Audio too long (max %s)", h.maxAudioDuration),
+			))
+		case errors.Is(err, media.ErrSilent):
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Audio is silent"))
+		case errors.Is(err, media.ErrNoDecoder):
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Only WAV audio can be processed on this server"))
+		}
 		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to save file"))
 	}
 
-	// Return response
-	url := "/uploads/audio/" + filename
-	return c.JSON(http.StatusCreated, map[string]interface{}{
-		"url":      url,
-		"filename": filename,
-		"size":     file.Size,
-		"mimeType": mimeType,
-	})
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, uploadResponse(h.signer, asset, !created))
 }
 
 // Helper functions
 
-func isValidImageType(mimeType string) bool {
-	validTypes := []string{
-		"image/jpeg",
-		"image/png",
-		"image/webp",
-		"application/octet-stream",
+var (
+	errNoFile       = errors.New("no file uploaded")
+	errFileTooLarge = errors.New("file exceeds the size limit")
+)
+
+// mediaMaxAge is how long clients may cache a media file
+const mediaMaxAge = 365 * 24 * time.Hour
+
+// uploadResponse describes a stored asset, with signed URLs. For an image,
+// url, size and the dimensions are those of its full variant in the original
+// format.
+func uploadResponse(signer *storage.URLSigner, asset *models.MediaAsset, deduplicated bool) map[string]interface{} {
+	// The filename is the URL's path below the kind's directory
+	dir := "/images/"
+	if asset.Kind == media.KindAudio {
+		dir = "/audio/"
 	}
-	for _, t := range validTypes {
-		if t == mimeType {
-			return true
-		}
+	filename := asset.URL
+	if i := strings.LastIndex(asset.URL, dir); i >= 0 {
+		filename = asset.URL[i+len(dir):]
 	}
-	return false
-}
 
-func isValidImageExtension(ext string) bool {
-	validExts := []string{".jpg", ".jpeg", ".png", ".webp"}
-	for _, e := range validExts {
-		if e == ext {
-			return true
-		}
+	response := map[string]interface{}{
+		"assetId":      asset.ID,
+		"url":          signer.Sign(asset.URL),
+		"filename":     filename,
+		"size":         asset.Size,
+		"mimeType":     asset.MIMEType,
+		"deduplicated": deduplicated,
 	}
-	return false
-}
-
-func isValidAudioType(mimeType string) bool {
-	validTypes := []string{
-		"audio/mpeg",
-		"audio/mp3",
-		"audio/wav",
-		"audio/wave",
-		"audio/x-wav",
-		"audio/webm",
-		"application/octet-stream",
-	}
-	for _, t := range validTypes {
-		if t == mimeType {
-			return true
+	if asset.Kind != media.KindImage {
+		if asset.Duration != nil {
+			response["duration"] = *asset.Duration
 		}
+		return response
 	}
-	return false
-}
 
-func isValidAudioExtension(ext string) bool {
-	validExts := []string{".mp3", ".wav", ".webm"}
-	for _, e := range validExts {
-		if e == ext {
-			return true
+	response["width"] = asset.Width
+	response["height"] = asset.Height
+	if len(asset.Variants) > 0 {
+		response["imageSet"] = media.ImageSetFor(&asset.URL).Map(signer.Sign)
+		variants := make([]models.MediaVariant, len(asset.Variants))
+		for i, v := range asset.Variants {
+			if v.URL == asset.URL {
+				response["size"] = v.Size
+			}
+			v.URL = signer.Sign(v.URL)
+			variants[i] = v
 		}
+		response["variants"] = variants
 	}
-	return false
+	return response
 }
 
-func saveUploadedFile(file *multipart.FileHeader, savePath string) error {
-	src, err := file.Open()
+// readUploadedFile streams the multipart body up to the file field, skipping
+// any other fields, and reads at most maxSize bytes of the file, since its
+// declared size can lie. Nothing is buffered beyond that or spooled to disk.
+func readUploadedFile(c echo.Context, maxSize int64) ([]byte, error) {
+	reader, err := c.Request().MultipartReader()
 	if err != nil {
-		return err
+		return nil, errNoFile
 	}
-	defer src.Close()
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, errNoFile
+		}
+		if err != nil {
+			return nil, bodyReadError(err)
+		}
+		if part.FormName() != "file" {
+			part.Close()
+			continue
+		}
 
-	dst, err := os.Create(savePath)
-	if err != nil {
-		return err
+		data, err := io.ReadAll(io.LimitReader(part, maxSize+1))
+		part.Close()
+		if err != nil {
+			return nil, bodyReadError(err)
+		}
+		if int64(len(data)) > maxSize {
+			return nil, errFileTooLarge
+		}
+		return data, nil
 	}
-	defer dst.Close()
+}
 
-	if _, err = io.Copy(dst, src); err != nil {
-		return err
+// bodyReadError reports a body cut off by the route's body limit as too large
+func bodyReadError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errFileTooLarge
 	}
-
-	return nil
+	return err
 }
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/utils"
+)
+
+type PublishHandler struct {
+	publishService services.PublishService
+	journeyService services.JourneyService
+}
+
+func NewPublishHandler(publishService services.PublishService, journeyService services.JourneyService) *PublishHandler {
+	return &PublishHandler{
+		publishService: publishService,
+		journeyService: journeyService,
+	}
+}
+
+// ValidateJourney handles GET /api/v1/journeys/:id/publish/check
+func (h *PublishHandler) ValidateJourney(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	journey, err := h.journeyService.GetJourneyByID(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+	}
+	if journey.CreatedBy != userID {
+		return forbidden(c, "publish", "journey")
+	}
+
+	report, err := h.publishService.ValidateJourney(id)
+	if err != nil {
+		if err.Error() == "journey not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to validate journey"))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(report))
+}
+
+// PublishJourney handles POST /api/v1/journeys/:id/publish
+func (h *PublishHandler) PublishJourney(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	journey, err := h.journeyService.GetJourneyByID(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+	}
+	if journey.CreatedBy != userID {
+		return forbidden(c, "publish", "journey")
+	}
+
+	var req services.PublishRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	result, err := h.publishService.Publish(id, userID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrPublishBlocked) {
+			response := utils.ErrorResponse(err.Error())
+			response["report"] = result.Report
+			return c.JSON(http.StatusUnprocessableEntity, response)
+		}
+		return h.transitionError(c, err, "Failed to publish journey")
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
+
+// UnpublishJourney handles POST /api/v1/journeys/:id/unpublish
+func (h *PublishHandler) UnpublishJourney(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	journey, err := h.journeyService.GetJourneyByID(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+	}
+	if journey.CreatedBy != userID {
+		return forbidden(c, "unpublish", "journey")
+	}
+
+	journey, err = h.publishService.Unpublish(id)
+	if err != nil {
+		return h.transitionError(c, err, "Failed to unpublish journey")
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(journey))
+}
+
+// ArchiveJourney handles POST /api/v1/journeys/:id/archive
+func (h *PublishHandler) ArchiveJourney(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	journey, err := h.journeyService.GetJourneyByID(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+	}
+	if journey.CreatedBy != userID {
+		return forbidden(c, "archive", "journey")
+	}
+
+	journey, err = h.publishService.Archive(id)
+	if err != nil {
+		return h.transitionError(c, err, "Failed to archive journey")
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(journey))
+}
+
+// transitionError maps a failed status change to a response
+func (h *PublishHandler) transitionError(c echo.Context, err error, fallback string) error {
+	switch {
+	case err.Error() == "journey not found":
+		return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+	case strings.HasPrefix(err.Error(), "cannot move journey"),
+		strings.HasPrefix(err.Error(), "journey status changed"):
+		return c.JSON(http.StatusConflict, utils.ErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusInternalServerError, utils.ErrorResponse(fallback))
+}
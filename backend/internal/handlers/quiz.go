@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/utils"
+)
+
+type QuizHandler struct {
+	quizService     services.QuizService
+	scenarioService services.ScenarioService
+}
+
+func NewQuizHandler(quizService services.QuizService, scenarioService services.ScenarioService) *QuizHandler {
+	return &QuizHandler{
+		quizService:     quizService,
+		scenarioService: scenarioService,
+	}
+}
+
+// GenerateQuiz handles POST /api/v1/scenarios/:id/quiz/generate
+func (h *QuizHandler) GenerateQuiz(c echo.Context) error {
+	scenarioID := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	ownerID, err := h.scenarioService.GetOwnerID(scenarioID)
+	if err != nil {
+		if isNotFound(err) {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Scenario not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch scenario"))
+	}
+	if ownerID != userID {
+		return forbidden(c, "generate quizzes for", "scenario")
+	}
+
+	var req services.GenerateQuizRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	quiz, err := h.quizService.GenerateQuiz(scenarioID, req)
+	if err != nil {
+		if err.Error() == "scenario not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Scenario not found"))
+		}
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusCreated, utils.SuccessResponse(quiz))
+}
+
+// GetQuiz handles GET /api/v1/quizzes/:id
+func (h *QuizHandler) GetQuiz(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("userId").(string)
+	publishedOnly := c.Get("userRole") != "admin"
+
+	quiz, err := h.quizService.GetQuiz(id, userID, publishedOnly)
+	if err != nil {
+		if err.Error() == "quiz not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Quiz not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch quiz"))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(quiz))
+}
+
+// SubmitQuiz handles POST /api/v1/quizzes/:id/submit
+func (h *QuizHandler) SubmitQuiz(c echo.Context) error {
+	id := c.Param("id")
+	userID := c.Get("userId").(string)
+	publishedOnly := c.Get("userRole") != "admin"
+
+	var req services.SubmitQuizRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	result, err := h.quizService.SubmitQuiz(userID, id, req, publishedOnly)
+	if err != nil {
+		if err.Error() == "quiz not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Quiz not found"))
+		}
+		if err.Error() == "quiz has no questions" {
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to submit quiz"))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(result))
+}
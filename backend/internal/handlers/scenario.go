@@ -6,19 +6,28 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/learng/backend/internal/models"
 	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/storage"
 	"github.com/learng/backend/internal/utils"
 )
 
 type ScenarioHandler struct {
 	scenarioService services.ScenarioService
+	journeyService  services.JourneyService
+	signer          *storage.URLSigner
 }
 
-func NewScenarioHandler(scenarioService services.ScenarioService) *ScenarioHandler {
-	return &ScenarioHandler{scenarioService: scenarioService}
+func NewScenarioHandler(scenarioService services.ScenarioService, journeyService services.JourneyService, signer *storage.URLSigner) *ScenarioHandler {
+	return &ScenarioHandler{
+		scenarioService: scenarioService,
+		journeyService:  journeyService,
+		signer:          signer,
+	}
 }
 
 // CreateScenario handles POST /api/v1/scenarios
 func (h *ScenarioHandler) CreateScenario(c echo.Context) error {
+	userID := c.Get("userId").(string)
+
 	var req struct {
 		JourneyID    string `json:"journeyId"`
 		Title        string `json:"title"`
@@ -30,6 +39,17 @@ func (h *ScenarioHandler) CreateScenario(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
 	}
 
+	// Scenarios can only be added to the caller's own journeys
+	if req.JourneyID != "" {
+		journey, err := h.journeyService.GetJourneyByID(req.JourneyID)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+		}
+		if journey.CreatedBy != userID {
+			return forbidden(c, "add scenarios to", "journey")
+		}
+	}
+
 	scenario := &models.Scenario{
 		JourneyID:    req.JourneyID,
 		Title:        req.Title,
@@ -56,12 +76,27 @@ func (h *ScenarioHandler) GetScenarioByID(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch scenario"))
 	}
 
-	return c.JSON(http.StatusOK, utils.SuccessResponse(scenario))
+	return c.JSON(http.StatusOK, utils.SuccessResponse(scenarioResponse{
+		Scenario: scenario,
+		Words:    signWords(h.signer, scenario.Words),
+	}))
 }
 
 // UpdateScenario handles PUT /api/v1/scenarios/:id
 func (h *ScenarioHandler) UpdateScenario(c echo.Context) error {
 	id := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	ownerID, err := h.scenarioService.GetOwnerID(id)
+	if err != nil {
+		if isNotFound(err) {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Scenario not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch scenario"))
+	}
+	if ownerID != userID {
+		return forbidden(c, "update", "scenario")
+	}
 
 	var updates map[string]interface{}
 	if err := c.Bind(&updates); err != nil {
@@ -82,6 +117,18 @@ func (h *ScenarioHandler) UpdateScenario(c echo.Context) error {
 // DeleteScenario handles DELETE /api/v1/scenarios/:id
 func (h *ScenarioHandler) DeleteScenario(c echo.Context) error {
 	id := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	ownerID, err := h.scenarioService.GetOwnerID(id)
+	if err != nil {
+		if isNotFound(err) {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Scenario not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch scenario"))
+	}
+	if ownerID != userID {
+		return forbidden(c, "delete", "scenario")
+	}
 
 	if err := h.scenarioService.DeleteScenario(id); err != nil {
 		if err.Error() == "scenario not found" {
@@ -92,3 +139,34 @@ func (h *ScenarioHandler) DeleteScenario(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// ReorderScenarios handles PUT /api/v1/journeys/:id/scenarios/order
+func (h *ScenarioHandler) ReorderScenarios(c echo.Context) error {
+	journeyID := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	journey, err := h.journeyService.GetJourneyByID(journeyID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+	}
+	if journey.CreatedBy != userID {
+		return forbidden(c, "reorder scenarios in", "journey")
+	}
+
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	scenarios, err := h.scenarioService.ReorderScenarios(journeyID, req.IDs)
+	if err != nil {
+		if err.Error() == "journey not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+		}
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(scenarios))
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"github.com/learng/backend/internal/media"
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/storage"
+)
+
+// Stored media is served only to requests with an access token in the
+// Authorization header or through signed URLs, and image and audio elements
+// cannot set headers. Responses that show media therefore carry signed URLs,
+// as the learner views do. The word service stores a signed URL sent back in
+// its plain form.
+
+// wordResponse is a word as responses show it, with the variant URLs derived
+// from its image URL
+type wordResponse struct {
+	*models.Word
+	ImageSet *media.ImageSet `json:"imageSet,omitempty"`
+}
+
+type scenarioResponse struct {
+	*models.Scenario
+	Words []wordResponse `json:"words,omitempty"`
+}
+
+type wordImportResponse struct {
+	*services.WordImportResult
+	Words []wordResponse `json:"words"`
+}
+
+func signWord(signer *storage.URLSigner, word *models.Word) wordResponse {
+	imageSet := media.ImageSetFor(word.ImageURL).Map(signer.Sign)
+	word.ImageURL = signedURL(signer, word.ImageURL)
+	word.AudioURL = signedURL(signer, word.AudioURL)
+	return wordResponse{Word: word, ImageSet: imageSet}
+}
+
+func signWords(signer *storage.URLSigner, words []models.Word) []wordResponse {
+	signed := make([]wordResponse, len(words))
+	for i := range words {
+		signed[i] = signWord(signer, &words[i])
+	}
+	return signed
+}
+
+func signAsset(signer *storage.URLSigner, asset *models.MediaAsset) {
+	asset.URL = signer.Sign(asset.URL)
+	for i := range asset.Variants {
+		asset.Variants[i].URL = signer.Sign(asset.Variants[i].URL)
+	}
+}
+
+// signVersion signs the media of a version's snapshot, which is decoded for
+// each response and so safe to change
+func signVersion(signer *storage.URLSigner, version *services.JourneyVersionView) {
+	if version.Content == nil {
+		return
+	}
+	for i := range version.Content.Scenarios {
+		words := version.Content.Scenarios[i].Words
+		for j := range words {
+			words[j].ImageURL = signedURL(signer, words[j].ImageURL)
+			words[j].AudioURL = signedURL(signer, words[j].AudioURL)
+		}
+	}
+}
+
+func signedURL(signer *storage.URLSigner, url *string) *string {
+	if url == nil || *url == "" {
+		return url
+	}
+	signed := signer.Sign(*url)
+	return &signed
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/utils"
+)
+
+type TrashHandler struct {
+	trashService services.TrashService
+}
+
+func NewTrashHandler(trashService services.TrashService) *TrashHandler {
+	return &TrashHandler{trashService: trashService}
+}
+
+// GetTrash handles GET /api/v1/trash
+func (h *TrashHandler) GetTrash(c echo.Context) error {
+	userID := c.Get("userId").(string)
+
+	items, err := h.trashService.GetTrash(userID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch trash"))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(items))
+}
+
+// RestoreJourney handles POST /api/v1/journeys/:id/restore
+func (h *TrashHandler) RestoreJourney(c echo.Context) error {
+	return h.restore(c, services.TrashTypeJourney, h.trashService.RestoreJourney)
+}
+
+// RestoreScenario handles POST /api/v1/scenarios/:id/restore
+func (h *TrashHandler) RestoreScenario(c echo.Context) error {
+	return h.restore(c, services.TrashTypeScenario, h.trashService.RestoreScenario)
+}
+
+// RestoreWord handles POST /api/v1/words/:id/restore
+func (h *TrashHandler) RestoreWord(c echo.Context) error {
+	return h.restore(c, services.TrashTypeWord, h.trashService.RestoreWord)
+}
+
+// restore checks the caller owns the deleted item and restores its subtree
+func (h *TrashHandler) restore(c echo.Context, itemType string, restore func(id string) error) error {
+	id := c.Param("id")
+	userID := c.Get("userId").(string)
+	label := strings.ToUpper(itemType[:1]) + itemType[1:]
+
+	ownerID, err := h.trashService.GetOwnerID(itemType, id)
+	if err != nil {
+		if isNotFound(err) {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse(label+" not found in trash"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch "+itemType))
+	}
+	if ownerID != userID {
+		return forbidden(c, "restore", itemType)
+	}
+
+	if err := restore(id); err != nil {
+		if isNotFound(err) {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse(label+" not found in trash"))
+		}
+		if strings.Contains(err.Error(), "restore the") {
+			return c.JSON(http.StatusConflict, utils.ErrorResponse(err.Error()))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to restore "+itemType))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(map[string]string{
+		"type": itemType,
+		"id":   id,
+	}))
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/storage"
+	"github.com/learng/backend/internal/utils"
+)
+
+type VersionHandler struct {
+	versionService services.VersionService
+	journeyService services.JourneyService
+	signer         *storage.URLSigner
+}
+
+func NewVersionHandler(versionService services.VersionService, journeyService services.JourneyService, signer *storage.URLSigner) *VersionHandler {
+	return &VersionHandler{
+		versionService: versionService,
+		journeyService: journeyService,
+		signer:         signer,
+	}
+}
+
+// GetVersions handles GET /api/v1/journeys/:id/versions
+func (h *VersionHandler) GetVersions(c echo.Context) error {
+	id := c.Param("id")
+
+	versions, err := h.versionService.ListVersions(id)
+	if err != nil {
+		return h.versionError(c, err, "Failed to fetch versions")
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(versions))
+}
+
+// GetVersion handles GET /api/v1/journeys/:id/versions/:versionId
+func (h *VersionHandler) GetVersion(c echo.Context) error {
+	id := c.Param("id")
+	versionID := c.Param("versionId")
+
+	version, err := h.versionService.GetVersion(id, versionID)
+	if err != nil {
+		return h.versionError(c, err, "Failed to fetch version")
+	}
+
+	signVersion(h.signer, version)
+	return c.JSON(http.StatusOK, utils.SuccessResponse(version))
+}
+
+// DiffDraft handles GET /api/v1/journeys/:id/versions/diff
+// Compares the draft with ?versionId=, or with the live version by default.
+func (h *VersionHandler) DiffDraft(c echo.Context) error {
+	id := c.Param("id")
+
+	diff, err := h.versionService.DiffDraft(id, c.QueryParam("versionId"))
+	if err != nil {
+		return h.versionError(c, err, "Failed to compare versions")
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(diff))
+}
+
+// RollbackVersion handles POST /api/v1/journeys/:id/versions/:versionId/rollback
+func (h *VersionHandler) RollbackVersion(c echo.Context) error {
+	id := c.Param("id")
+	versionID := c.Param("versionId")
+	userID := c.Get("userId").(string)
+
+	journey, err := h.journeyService.GetJourneyByID(id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+	}
+	if journey.CreatedBy != userID {
+		return forbidden(c, "roll back", "journey")
+	}
+
+	version, err := h.versionService.Rollback(id, versionID, userID)
+	if err != nil {
+		return h.versionError(c, err, "Failed to roll back journey")
+	}
+
+	signVersion(h.signer, version)
+	return c.JSON(http.StatusOK, utils.SuccessResponse(version))
+}
+
+// versionError maps a version service error to a response
+func (h *VersionHandler) versionError(c echo.Context, err error, fallback string) error {
+	switch err.Error() {
+	case "journey not found":
+		return c.JSON(http.StatusNotFound, utils.ErrorResponse("Journey not found"))
+	case "version not found":
+		return c.JSON(http.StatusNotFound, utils.ErrorResponse("Version not found"))
+	case "journey has no published version":
+		return c.JSON(http.StatusNotFound, utils.ErrorResponse(err.Error()))
+	case "only a published journey can be rolled back",
+		"version is already live",
+		"journey status changed concurrently; reload and try again":
+		return c.JSON(http.StatusConflict, utils.ErrorResponse(err.Error()))
+	}
+	return c.JSON(http.StatusInternalServerError, utils.ErrorResponse(fallback))
+}
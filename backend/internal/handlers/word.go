@@ -1,24 +1,38 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/learng/backend/internal/models"
 	"github.com/learng/backend/internal/services"
+	"github.com/learng/backend/internal/storage"
 	"github.com/learng/backend/internal/utils"
 )
 
 type WordHandler struct {
-	wordService services.WordService
+	wordService     services.WordService
+	scenarioService services.ScenarioService
+	signer          *storage.URLSigner
 }
 
-func NewWordHandler(wordService services.WordService) *WordHandler {
-	return &WordHandler{wordService: wordService}
+func NewWordHandler(wordService services.WordService, scenarioService services.ScenarioService, signer *storage.URLSigner) *WordHandler {
+	return &WordHandler{
+		wordService:     wordService,
+		scenarioService: scenarioService,
+		signer:          signer,
+	}
 }
 
 // CreateWord handles POST /api/v1/words
 func (h *WordHandler) CreateWord(c echo.Context) error {
+	userID := c.Get("userId").(string)
+
 	var req struct {
 		ScenarioID       string  `json:"scenarioId"`
 		TargetText       string  `json:"targetText"`
@@ -26,6 +40,8 @@ func (h *WordHandler) CreateWord(c echo.Context) error {
 		DisplayOrder     int     `json:"displayOrder"`
 		ImageURL         *string `json:"imageUrl"`
 		AudioURL         *string `json:"audioUrl"`
+		ImageAssetID     *string `json:"imageAssetId"`
+		AudioAssetID     *string `json:"audioAssetId"`
 		GenerationMethod string  `json:"generationMethod"`
 	}
 
@@ -33,6 +49,20 @@ func (h *WordHandler) CreateWord(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
 	}
 
+	// Words can only be added to scenarios of the caller's own journeys
+	if req.ScenarioID != "" {
+		ownerID, err := h.scenarioService.GetOwnerID(req.ScenarioID)
+		if err != nil {
+			if isNotFound(err) {
+				return c.JSON(http.StatusNotFound, utils.ErrorResponse("Scenario not found"))
+			}
+			return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch scenario"))
+		}
+		if ownerID != userID {
+			return forbidden(c, "add words to", "scenario")
+		}
+	}
+
 	word := &models.Word{
 		ScenarioID:       req.ScenarioID,
 		TargetText:       req.TargetText,
@@ -40,6 +70,8 @@ func (h *WordHandler) CreateWord(c echo.Context) error {
 		DisplayOrder:     req.DisplayOrder,
 		ImageURL:         req.ImageURL,
 		AudioURL:         req.AudioURL,
+		ImageAssetID:     req.ImageAssetID,
+		AudioAssetID:     req.AudioAssetID,
 		GenerationMethod: req.GenerationMethod,
 	}
 
@@ -47,7 +79,7 @@ func (h *WordHandler) CreateWord(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
 	}
 
-	return c.JSON(http.StatusCreated, utils.SuccessResponse(word))
+	return c.JSON(http.StatusCreated, utils.SuccessResponse(signWord(h.signer, word)))
 }
 
 // GetWordByID handles GET /api/v1/words/:id
@@ -62,12 +94,24 @@ func (h *WordHandler) GetWordByID(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch word"))
 	}
 
-	return c.JSON(http.StatusOK, utils.SuccessResponse(word))
+	return c.JSON(http.StatusOK, utils.SuccessResponse(signWord(h.signer, word)))
 }
 
 // UpdateWord handles PUT /api/v1/words/:id
 func (h *WordHandler) UpdateWord(c echo.Context) error {
 	id := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	ownerID, err := h.wordService.GetOwnerID(id)
+	if err != nil {
+		if isNotFound(err) {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Word not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch word"))
+	}
+	if ownerID != userID {
+		return forbidden(c, "update", "word")
+	}
 
 	var updates map[string]interface{}
 	if err := c.Bind(&updates); err != nil {
@@ -82,12 +126,24 @@ func (h *WordHandler) UpdateWord(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
 	}
 
-	return c.JSON(http.StatusOK, utils.SuccessResponse(updatedWord))
+	return c.JSON(http.StatusOK, utils.SuccessResponse(signWord(h.signer, updatedWord)))
 }
 
 // DeleteWord handles DELETE /api/v1/words/:id
 func (h *WordHandler) DeleteWord(c echo.Context) error {
 	id := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	ownerID, err := h.wordService.GetOwnerID(id)
+	if err != nil {
+		if isNotFound(err) {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Word not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch word"))
+	}
+	if ownerID != userID {
+		return forbidden(c, "delete", "word")
+	}
 
 	if err := h.wordService.DeleteWord(id); err != nil {
 		if err.Error() == "word not found" {
@@ -98,3 +154,145 @@ func (h *WordHandler) DeleteWord(c echo.Context) error {
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// ReorderWords handles PUT /api/v1/scenarios/:id/words/order
+func (h *WordHandler) ReorderWords(c echo.Context) error {
+	scenarioID := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	ownerID, err := h.scenarioService.GetOwnerID(scenarioID)
+	if err != nil {
+		if isNotFound(err) {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Scenario not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch scenario"))
+	}
+	if ownerID != userID {
+		return forbidden(c, "reorder words in", "scenario")
+	}
+
+	var req struct {
+		IDs []string `json:"ids"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+	}
+
+	words, err := h.wordService.ReorderWords(scenarioID, req.IDs)
+	if err != nil {
+		if err.Error() == "scenario not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Scenario not found"))
+		}
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, utils.SuccessResponse(signWords(h.signer, words)))
+}
+
+// MaxImportSize bounds the size of an uploaded or pasted import. The import
+// route's body limit allows this plus room for the multipart framing.
+const MaxImportSize = 1 << 20 // 1MB
+
+// ImportWords handles POST /api/v1/scenarios/:id/words/import
+// Accepts either a multipart upload ("file" plus optional format, hasHeader,
+// dryRun and columns form fields) or a JSON body with pasted text.
+func (h *WordHandler) ImportWords(c echo.Context) error {
+	scenarioID := c.Param("id")
+	userID := c.Get("userId").(string)
+
+	ownerID, err := h.scenarioService.GetOwnerID(scenarioID)
+	if err != nil {
+		if isNotFound(err) {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Scenario not found"))
+		}
+		return c.JSON(http.StatusInternalServerError, utils.ErrorResponse("Failed to fetch scenario"))
+	}
+	if ownerID != userID {
+		return forbidden(c, "add words to", "scenario")
+	}
+
+	var req services.WordImportRequest
+	if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		if err := bindImportForm(c, &req); err != nil {
+			switch {
+			case errors.Is(err, errNoFile):
+				return c.JSON(http.StatusBadRequest, utils.ErrorResponse("No file uploaded"))
+			case errors.Is(err, errFileTooLarge):
+				return c.JSON(http.StatusBadRequest, utils.ErrorResponse(
+					fmt.Sprintf("File too large (max %dMB)", MaxImportSize/(1024*1024)),
+				))
+			case errors.Is(err, errInvalidColumns):
+				return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid columns mapping"))
+			}
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Failed to read file"))
+		}
+	} else {
+		if err := c.Bind(&req); err != nil {
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse("Invalid request body"))
+		}
+		if len(req.Text) > MaxImportSize {
+			return c.JSON(http.StatusBadRequest, utils.ErrorResponse(
+				fmt.Sprintf("Import too large (max %dMB)", MaxImportSize/(1024*1024)),
+			))
+		}
+	}
+
+	result, err := h.wordService.ImportWords(scenarioID, req)
+	if err != nil {
+		if err.Error() == "scenario not found" {
+			return c.JSON(http.StatusNotFound, utils.ErrorResponse("Scenario not found"))
+		}
+		return c.JSON(http.StatusBadRequest, utils.ErrorResponse(err.Error()))
+	}
+
+	status := http.StatusOK
+	if result.Imported > 0 {
+		status = http.StatusCreated
+	}
+	return c.JSON(status, utils.SuccessResponse(wordImportResponse{
+		WordImportResult: result,
+		Words:            signWords(h.signer, result.Words),
+	}))
+}
+
+var errInvalidColumns = errors.New("invalid columns mapping")
+
+// bindImportForm reads a multipart import upload into req
+func bindImportForm(c echo.Context, req *services.WordImportRequest) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		if err := bodyReadError(err); errors.Is(err, errFileTooLarge) {
+			return err
+		}
+		return errNoFile
+	}
+	if file.Size > MaxImportSize {
+		return errFileTooLarge
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, MaxImportSize))
+	if err != nil {
+		return err
+	}
+	req.Text = string(data)
+
+	req.Format = c.FormValue("format")
+	if req.Format == "" && strings.HasSuffix(strings.ToLower(file.Filename), ".tsv") {
+		req.Format = "tsv"
+	}
+	req.HasHeader = c.FormValue("hasHeader") == "true"
+	req.DryRun = c.FormValue("dryRun") == "true"
+
+	if columns := c.FormValue("columns"); columns != "" {
+		if err := json.Unmarshal([]byte(columns), &req.Columns); err != nil {
+			return errInvalidColumns
+		}
+	}
+	return nil
+}
@@ -0,0 +1,384 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+var ebmlMagic = []byte{0x1a, 0x45, 0xdf, 0xa3}
+
+// EBML element IDs, with their length marker bits kept
+const (
+	ebmlIDHeader        = 0x1a45dfa3
+	ebmlIDDocType       = 0x4282
+	ebmlIDSegment       = 0x18538067
+	ebmlIDInfo          = 0x1549a966
+	ebmlIDTimecodeScale = 0x2ad7b1
+	ebmlIDDuration      = 0x4489
+	ebmlIDCluster       = 0x1f43b675
+	ebmlIDTimecode      = 0xe7
+	ebmlIDBlockGroup    = 0xa0
+	ebmlIDBlock         = 0xa1
+	ebmlIDSimpleBlock   = 0xa3
+)
+
+// defaultTimecodeScale is the WebM timestamp unit in nanoseconds when the
+// segment does not declare one
+const defaultTimecodeScale = 1000000
+
+// MPEG audio layer III bitrates in kbit/s by bitrate index, for MPEG-1 and
+// for MPEG-2/2.5
+var (
+	mp3BitratesV1 = [15]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320}
+	mp3BitratesV2 = [15]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160}
+)
+
+// mp3SampleRates by version bits (MPEG-2.5, reserved, MPEG-2, MPEG-1) and
+// sample rate index
+var mp3SampleRates = [4][3]int{
+	{11025, 12000, 8000},
+	{},
+	{22050, 24000, 16000},
+	{44100, 48000, 32000},
+}
+
+func isWAV(data []byte) bool {
+	return len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WAVE"
+}
+
+func isWebM(data []byte) bool {
+	return bytes.HasPrefix(data, ebmlMagic)
+}
+
+func isMP3(data []byte) bool {
+	if bytes.HasPrefix(data, []byte("ID3")) {
+		return true
+	}
+	_, ok := mp3FrameLength(data)
+	return ok
+}
+
+// parseWAV checks the RIFF container holds a format chunk and a data chunk,
+// and takes the duration from the data size and the byte rate
+func parseWAV(data []byte, info *Info) error {
+	chunks, err := riffChunks(data)
+	if err != nil {
+		return err
+	}
+
+	hasFormat, hasData := false, false
+	byteRate := 0
+	for _, chunk := range chunks {
+		switch chunk.id {
+		case "fmt ":
+			if len(chunk.body) < 16 {
+				return malformed("wav", "short fmt chunk")
+			}
+			channels := binary.LittleEndian.Uint16(chunk.body[2:4])
+			sampleRate := binary.LittleEndian.Uint32(chunk.body[4:8])
+			byteRate = int(binary.LittleEndian.Uint32(chunk.body[8:12]))
+			if channels == 0 || sampleRate == 0 || byteRate == 0 {
+				return malformed("wav", "invalid fmt chunk")
+			}
+			hasFormat = true
+		case "data":
+			if !hasFormat {
+				return malformed("wav", "data chunk before fmt chunk")
+			}
+			hasData = true
+			info.Duration += float64(len(chunk.body)) / float64(byteRate)
+		}
+	}
+	if !hasFormat || !hasData {
+		return malformed("wav", "missing fmt or data chunk")
+	}
+	return nil
+}
+
+// parseWebM checks the EBML header declares the webm doctype and that the
+// segment, and each top-level element in it, fits in the file. Recorders that
+// stream write elements of unknown size, which are accepted as running to the
+// end of the file. The duration is read from the segment's info, or else from
+// the timestamp of the last block, since recorders that stream leave it out.
+func parseWebM(data []byte, info *Info) error {
+	id, size, pos, err := ebmlElement(data, 0)
+	if err != nil {
+		return err
+	}
+	if id != ebmlIDHeader || size < 0 || pos+size > len(data) {
+		return malformed("webm", "invalid EBML header")
+	}
+	headerEnd := pos + size
+
+	docType := ""
+	for pos < headerEnd {
+		childID, childSize, childPos, err := ebmlElement(data[:headerEnd], pos)
+		if err != nil || childSize < 0 || childPos+childSize > headerEnd {
+			return malformed("webm", "invalid EBML header")
+		}
+		if childID == ebmlIDDocType {
+			docType = string(bytes.TrimRight(data[childPos:childPos+childSize], "\x00"))
+		}
+		pos = childPos + childSize
+	}
+	if docType != "webm" {
+		return malformed("webm", "doctype is not webm")
+	}
+
+	id, size, pos, err = ebmlElement(data, headerEnd)
+	if err != nil {
+		return err
+	}
+	if id != ebmlIDSegment {
+		return malformed("webm", "missing segment")
+	}
+	segmentStart := pos
+	segmentEnd := len(data)
+	if size >= 0 {
+		segmentEnd = pos + size
+		if segmentEnd > len(data) {
+			return ErrTruncated
+		}
+		if trailing(data, segmentEnd) {
+			return ErrTrailingData
+		}
+	}
+
+	for pos < segmentEnd {
+		_, childSize, childPos, err := ebmlElement(data[:segmentEnd], pos)
+		if err != nil {
+			return err
+		}
+		if childSize < 0 {
+			break // unknown size: runs to the end of the segment
+		}
+		pos = childPos + childSize
+		if pos > segmentEnd {
+			return ErrTruncated
+		}
+	}
+	info.Duration = webmDuration(data[:segmentEnd], segmentStart)
+	return nil
+}
+
+// webmDuration walks the segment's elements from pos, descending into the
+// info, clusters and block groups, whose sizes may be unknown, and skipping
+// everything else
+func webmDuration(data []byte, pos int) float64 {
+	scale := uint64(defaultTimecodeScale)
+	declared := 0.0
+	var clusterTime, lastBlock int64
+
+	for pos < len(data) {
+		id, size, dataPos, err := ebmlElement(data, pos)
+		if err != nil {
+			break
+		}
+		switch id {
+		case ebmlIDInfo, ebmlIDCluster, ebmlIDBlockGroup:
+			pos = dataPos
+			continue
+		}
+		if size < 0 || dataPos+size > len(data) {
+			break
+		}
+		body := data[dataPos : dataPos+size]
+		switch id {
+		case ebmlIDTimecodeScale:
+			if v := ebmlUint(body); v > 0 {
+				scale = v
+			}
+		case ebmlIDDuration:
+			declared = ebmlFloat(body)
+		case ebmlIDTimecode:
+			clusterTime = int64(ebmlUint(body))
+		case ebmlIDBlock, ebmlIDSimpleBlock:
+			// Track number, then the timestamp relative to the cluster
+			if _, n, ok := ebmlVint(body, 0, false); ok && n+2 <= len(body) {
+				lastBlock = max(lastBlock, clusterTime+int64(int16(binary.BigEndian.Uint16(body[n:n+2]))))
+			}
+		}
+		pos = dataPos + size
+	}
+
+	if declared > 0 {
+		return declared * float64(scale) / 1e9
+	}
+	return float64(lastBlock) * float64(scale) / 1e9
+}
+
+// ebmlUint decodes a big-endian unsigned integer element
+func ebmlUint(body []byte) uint64 {
+	var v uint64
+	for _, b := range body {
+		v = v<<8 | uint64(b)
+	}
+	return v
+}
+
+// ebmlFloat decodes a 4 or 8 byte float element
+func ebmlFloat(body []byte) float64 {
+	switch len(body) {
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(body)))
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(body))
+	}
+	return 0
+}
+
+// ebmlElement reads the element header at pos, returning its ID, its data
+// size (-1 when unknown) and where its data starts
+func ebmlElement(data []byte, pos int) (id uint64, size int, dataPos int, err error) {
+	id, idLen, ok := ebmlVint(data, pos, true)
+	if !ok {
+		return 0, 0, 0, ErrTruncated
+	}
+	rawSize, sizeLen, ok := ebmlVint(data, pos+idLen, false)
+	if !ok {
+		return 0, 0, 0, ErrTruncated
+	}
+	dataPos = pos + idLen + sizeLen
+	if rawSize == 1<<(7*uint(sizeLen))-1 {
+		return id, -1, dataPos, nil
+	}
+	if rawSize > uint64(len(data)) {
+		return 0, 0, 0, ErrTruncated
+	}
+	return id, int(rawSize), dataPos, nil
+}
+
+// ebmlVint decodes a variable-length integer at pos. IDs keep their length
+// marker bit; sizes drop it.
+func ebmlVint(data []byte, pos int, keepMarker bool) (uint64, int, bool) {
+	if pos >= len(data) || data[pos] == 0 {
+		return 0, 0, false
+	}
+	length := 1
+	for mask := byte(0x80); data[pos]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 || pos+length > len(data) {
+		return 0, 0, false
+	}
+	value := uint64(data[pos])
+	if !keepMarker {
+		value &= uint64(0xff >> length)
+	}
+	for _, b := range data[pos+1 : pos+length] {
+		value = value<<8 | uint64(b)
+	}
+	return value, length, true
+}
+
+// parseMP3 skips a leading ID3v2 tag, then walks every MPEG layer III frame
+// to the end of the file, allowing only an ID3v1 tag after the last one. The
+// duration is the sum of the frames' durations; a leading Xing or Info frame
+// carries no audio and is not counted.
+func parseMP3(data []byte, info *Info) error {
+	pos := 0
+	if bytes.HasPrefix(data, []byte("ID3")) {
+		if len(data) < 10 {
+			return ErrTruncated
+		}
+		size := int(data[6]&0x7f)<<21 | int(data[7]&0x7f)<<14 | int(data[8]&0x7f)<<7 | int(data[9]&0x7f)
+		pos = 10 + size
+		if data[5]&0x10 != 0 { // footer present
+			pos += 10
+		}
+		if pos > len(data) {
+			return ErrTruncated
+		}
+		// Some taggers pad past the declared size
+		for pos < len(data) && data[pos] == 0 {
+			pos++
+		}
+	}
+
+	frames := 0
+	for pos < len(data) {
+		rest := data[pos:]
+		if length, ok := mp3FrameLength(rest); ok {
+			if length > len(rest) {
+				return ErrTruncated
+			}
+			if frames > 0 || !mp3InfoFrame(rest[:length]) {
+				info.Duration += mp3FrameSeconds(rest)
+			}
+			pos += length
+			frames++
+			continue
+		}
+		if frames > 0 && len(rest) == 128 && bytes.HasPrefix(rest, []byte("TAG")) {
+			break
+		}
+		if frames == 0 {
+			return malformed("mp3", "no MPEG audio frames")
+		}
+		if trailing(data, pos) {
+			return ErrTrailingData
+		}
+		break
+	}
+	if frames == 0 {
+		return malformed("mp3", "no MPEG audio frames")
+	}
+	return nil
+}
+
+// mp3FrameLength decodes the MPEG layer III frame header at the start of data
+// and returns the frame's length in bytes
+func mp3FrameLength(data []byte) (int, bool) {
+	if len(data) < 4 || data[0] != 0xff || data[1]&0xe0 != 0xe0 {
+		return 0, false
+	}
+	version := (data[1] >> 3) & 0x03
+	layer := (data[1] >> 1) & 0x03
+	bitrateIndex := data[2] >> 4
+	sampleRateIndex := (data[2] >> 2) & 0x03
+	padding := int(data[2]>>1) & 0x01
+
+	if version == 1 || layer != 1 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return 0, false
+	}
+
+	sampleRate := mp3SampleRates[version][sampleRateIndex]
+	if version == 3 {
+		return 144*mp3BitratesV1[bitrateIndex]*1000/sampleRate + padding, true
+	}
+	return 72*mp3BitratesV2[bitrateIndex]*1000/sampleRate + padding, true
+}
+
+// mp3FrameSeconds is the playing time of the frame whose header starts data:
+// 1152 samples for MPEG-1, 576 for MPEG-2 and 2.5
+func mp3FrameSeconds(data []byte) float64 {
+	version := (data[1] >> 3) & 0x03
+	sampleRate := mp3SampleRates[version][(data[2]>>2)&0x03]
+	if version == 3 {
+		return 1152 / float64(sampleRate)
+	}
+	return 576 / float64(sampleRate)
+}
+
+// mp3InfoFrame reports whether frame is the Xing or Info header encoders put
+// before the audio, found right after the frame's side information
+func mp3InfoFrame(frame []byte) bool {
+	mono := frame[3]>>6 == 3
+	offset := 4
+	switch {
+	case frame[1]&0x18 == 0x18 && mono:
+		offset += 17
+	case frame[1]&0x18 == 0x18:
+		offset += 32
+	case mono:
+		offset += 9
+	default:
+		offset += 17
+	}
+	if offset+4 > len(frame) {
+		return false
+	}
+	tag := string(frame[offset : offset+4])
+	return tag == "Xing" || tag == "Info"
+}
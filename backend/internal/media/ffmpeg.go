@@ -0,0 +1,92 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Compressed audio is decoded by ffmpeg to raw 16-bit mono PCM at
+// decodeSampleRate. Raw output needs no header, so nothing depends on ffmpeg
+// seeking back in a pipe to fill in a WAV header's sizes.
+const (
+	decodeSampleRate = 24000
+	decodeTimeout    = 30 * time.Second
+	// maxDecoded bounds the decoded length, and so the memory a small but
+	// long, low-bitrate file can claim, before silence is trimmed
+	maxDecoded = 5 * time.Minute
+)
+
+// FFmpeg decodes MP3, WebM and WAV encodings other than PCM by running the
+// ffmpeg binary, which reads the file on stdin and writes samples on stdout
+type FFmpeg struct {
+	path string
+}
+
+// NewFFmpeg resolves the ffmpeg binary, given as a path or a name to look up
+// on PATH
+func NewFFmpeg(path string) (*FFmpeg, error) {
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FFmpeg{path: resolved}, nil
+}
+
+// Path returns the resolved path of the binary
+func (f *FFmpeg) Path() string {
+	return f.path
+}
+
+func (f *FFmpeg) decode(data []byte) (*pcm, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), decodeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, f.path,
+		"-hide_banner", "-loglevel", "error",
+		"-i", "pipe:0",
+		"-vn", "-ac", "1", "-ar", fmt.Sprint(decodeSampleRate),
+		"-f", "s16le", "-acodec", "pcm_s16le",
+		"pipe:1",
+	)
+	var stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run ffmpeg: %w", err)
+	}
+
+	maxBytes := int64(maxDecoded.Seconds()*decodeSampleRate) * 2
+	raw, err := io.ReadAll(io.LimitReader(stdout, maxBytes+1))
+	if err != nil || int64(len(raw)) > maxBytes {
+		cmd.Process.Kill()
+		cmd.Wait()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ffmpeg output: %w", err)
+		}
+		return nil, fmt.Errorf("%w: decodes to more than %s", ErrTooLong, maxDecoded)
+	}
+	if err := cmd.Wait(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("ffmpeg timed out after %s", decodeTimeout)
+		}
+		message, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n")
+		return nil, fmt.Errorf("%w: ffmpeg: %s", ErrMalformed, message)
+	}
+
+	p := &pcm{samples: make([]float64, len(raw)/2), sampleRate: decodeSampleRate}
+	for i := range p.samples {
+		p.samples[i] = float64(int16(binary.LittleEndian.Uint16(raw[2*i:]))) / 32768
+	}
+	return p, nil
+}
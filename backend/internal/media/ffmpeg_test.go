@@ -0,0 +1,95 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+// fakeFFmpegEnv makes the test binary act as ffmpeg, so the decoder's
+// plumbing is tested without ffmpeg installed. Its value picks the behavior.
+const fakeFFmpegEnv = "MEDIA_TEST_FAKE_FFMPEG"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(fakeFFmpegEnv); mode != "" {
+		os.Exit(fakeFFmpeg(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// fakeFFmpeg reads the input like ffmpeg and writes half a second of silence
+// either side of a one second tone as raw PCM, or fails
+func fakeFFmpeg(mode string) int {
+	io.Copy(io.Discard, os.Stdin)
+	if mode == "fail" {
+		os.Stderr.WriteString("pipe:0: Invalid data found when processing input\n")
+		return 1
+	}
+	samples := make([]byte, 2*2*decodeSampleRate)
+	for i := decodeSampleRate / 2; i < 3*decodeSampleRate/2; i++ {
+		v := int16(8000 * math.Sin(2*math.Pi*440*float64(i)/decodeSampleRate))
+		binary.LittleEndian.PutUint16(samples[2*i:], uint16(v))
+	}
+	os.Stdout.Write(samples)
+	return 0
+}
+
+func TestFFmpegDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		mode string
+		data []byte
+		err  error
+	}{
+		{name: "mp3", mode: "tone", data: testMP3(10)},
+		{name: "webm", mode: "tone", data: testWebM(1500)},
+		{name: "decoder error", mode: "fail", data: testMP3(10), err: ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(fakeFFmpegEnv, tt.mode)
+			decoder, err := NewFFmpeg(os.Args[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			info, err := Detect(tt.data)
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+
+			data, out, err := ProcessAudio(tt.data, info, 0, decoder)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ProcessAudio() error = %v, want %v", err, tt.err)
+				}
+				if !strings.Contains(err.Error(), "Invalid data") {
+					t.Errorf("error %q does not carry ffmpeg's message", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessAudio() error = %v", err)
+			}
+			if out.MIME != MIMEWAV {
+				t.Errorf("MIME = %s, want %s", out.MIME, MIMEWAV)
+			}
+			if want := 1 + 2*silencePadding; math.Abs(out.Duration-want) > 0.02 {
+				t.Errorf("Duration = %v, want %v", out.Duration, want)
+			}
+			if _, err := Detect(data); err != nil {
+				t.Errorf("processed clip does not detect: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewFFmpegMissing(t *testing.T) {
+	if _, err := NewFFmpeg("/nonexistent/ffmpeg"); err == nil {
+		t.Fatal("NewFFmpeg() found a missing binary")
+	}
+}
@@ -0,0 +1,191 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+)
+
+var pngSignature = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+func isJPEG(data []byte) bool {
+	return len(data) >= 3 && data[0] == 0xff && data[1] == 0xd8 && data[2] == 0xff
+}
+
+func isPNG(data []byte) bool {
+	return bytes.HasPrefix(data, pngSignature)
+}
+
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+}
+
+// parseJPEG walks the marker segments up to EOI, reading the frame size from
+// the first SOF segment
+func parseJPEG(data []byte, info *Info) error {
+	pos := 2
+	for {
+		if pos >= len(data) {
+			return ErrTruncated
+		}
+		if data[pos] != 0xff {
+			return malformed("jpeg", "expected a marker")
+		}
+		for pos < len(data) && data[pos] == 0xff {
+			pos++
+		}
+		if pos >= len(data) {
+			return ErrTruncated
+		}
+		marker := data[pos]
+		pos++
+
+		switch {
+		case marker == 0xd9: // EOI
+			if info.Width == 0 || info.Height == 0 {
+				return malformed("jpeg", "no frame header")
+			}
+			if trailing(data, pos) {
+				return ErrTrailingData
+			}
+			return nil
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			continue // standalone markers carry no length
+		}
+
+		if pos+2 > len(data) {
+			return ErrTruncated
+		}
+		length := int(binary.BigEndian.Uint16(data[pos:]))
+		if length < 2 {
+			return malformed("jpeg", "invalid segment length")
+		}
+		if pos+length > len(data) {
+			return ErrTruncated
+		}
+		segment := data[pos+2 : pos+length]
+
+		// SOF0-SOF15, except DHT (C4), JPG (C8) and DAC (CC)
+		if marker >= 0xc0 && marker <= 0xcf && marker != 0xc4 && marker != 0xc8 && marker != 0xcc && info.Width == 0 {
+			if len(segment) < 5 {
+				return malformed("jpeg", "short frame header")
+			}
+			info.Height = int(binary.BigEndian.Uint16(segment[1:3]))
+			info.Width = int(binary.BigEndian.Uint16(segment[3:5]))
+		}
+		if marker == 0xe1 && info.exif == nil && bytes.HasPrefix(segment, exifHeader) { // APP1
+			info.exif = segment[len(exifHeader):]
+		}
+		pos += length
+
+		if marker == 0xda { // SOS: skip entropy-coded data to the next real marker
+			for {
+				if pos+1 >= len(data) {
+					return ErrTruncated
+				}
+				if data[pos] == 0xff && data[pos+1] != 0 && (data[pos+1] < 0xd0 || data[pos+1] > 0xd7) {
+					break
+				}
+				pos++
+			}
+		}
+	}
+}
+
+// parsePNG walks the chunks up to IEND, checking every CRC and reading the
+// image size from IHDR
+func parsePNG(data []byte, info *Info) error {
+	pos := len(pngSignature)
+	first := true
+	for {
+		if pos+8 > len(data) {
+			return ErrTruncated
+		}
+		length := binary.BigEndian.Uint32(data[pos:])
+		if length > 1<<31-1 {
+			return malformed("png", "invalid chunk length")
+		}
+		end := pos + 12 + int(length)
+		if end > len(data) {
+			return ErrTruncated
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		body := data[pos+8 : pos+8+int(length)]
+		if crc32.ChecksumIEEE(data[pos+4:pos+8+int(length)]) != binary.BigEndian.Uint32(data[end-4:]) {
+			return malformed("png", "bad checksum in "+chunkType+" chunk")
+		}
+
+		if first {
+			if chunkType != "IHDR" || length != 13 {
+				return malformed("png", "missing IHDR chunk")
+			}
+			info.Width = int(binary.BigEndian.Uint32(body[0:4]))
+			info.Height = int(binary.BigEndian.Uint32(body[4:8]))
+			first = false
+		}
+		if chunkType == "eXIf" {
+			info.exif = body
+		}
+		pos = end
+
+		if chunkType == "IEND" {
+			if trailing(data, pos) {
+				return ErrTrailingData
+			}
+			return nil
+		}
+	}
+}
+
+// parseWebP checks the RIFF container and reads the canvas size from the
+// first VP8, VP8L or VP8X chunk
+func parseWebP(data []byte, info *Info) error {
+	chunks, err := riffChunks(data)
+	if err != nil {
+		return err
+	}
+
+	hasImage := false
+	for _, chunk := range chunks {
+		body := chunk.body
+		switch chunk.id {
+		case "VP8X":
+			if len(body) < 10 {
+				return malformed("webp", "short VP8X chunk")
+			}
+			if info.Width == 0 {
+				info.Width = 1 + int(uint24(body[4:7]))
+				info.Height = 1 + int(uint24(body[7:10]))
+			}
+		case "VP8 ":
+			if len(body) < 10 || body[3] != 0x9d || body[4] != 0x01 || body[5] != 0x2a {
+				return malformed("webp", "invalid VP8 frame header")
+			}
+			if info.Width == 0 {
+				info.Width = int(binary.LittleEndian.Uint16(body[6:8]) & 0x3fff)
+				info.Height = int(binary.LittleEndian.Uint16(body[8:10]) & 0x3fff)
+			}
+			hasImage = true
+		case "VP8L":
+			if len(body) < 5 || body[0] != 0x2f {
+				return malformed("webp", "invalid VP8L header")
+			}
+			if info.Width == 0 {
+				bits := binary.LittleEndian.Uint32(body[1:5])
+				info.Width = 1 + int(bits&0x3fff)
+				info.Height = 1 + int((bits>>14)&0x3fff)
+			}
+			hasImage = true
+		case "EXIF":
+			info.exif = bytes.TrimPrefix(body, exifHeader)
+		}
+	}
+	if !hasImage || info.Width == 0 || info.Height == 0 {
+		return malformed("webp", "no image data")
+	}
+	return nil
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16
+}
@@ -0,0 +1,151 @@
+// Package media identifies uploaded image and audio files by their content,
+// checks that each is a single, complete file of the format it claims, and
+// produces the resized variants served for images and the trimmed,
+// loudness-normalized clips served for audio.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Media kinds, which double as the upload subdirectory names
+const (
+	KindImage = "image"
+	KindAudio = "audio"
+)
+
+// Detected MIME types
+const (
+	MIMEJPEG = "image/jpeg"
+	MIMEPNG  = "image/png"
+	MIMEWebP = "image/webp"
+	MIMEMP3  = "audio/mpeg"
+	MIMEWAV  = "audio/wav"
+	MIMEWebM = "audio/webm"
+)
+
+var (
+	ErrUnsupported    = errors.New("unsupported media format")
+	ErrMalformed      = errors.New("malformed media file")
+	ErrTruncated      = errors.New("media file is truncated")
+	ErrTrailingData   = errors.New("media file has data after its end")
+	ErrEmbeddedMarkup = errors.New("media file contains embedded markup")
+	ErrWrongKind      = errors.New("media file is not of the expected kind")
+	ErrTooLarge       = errors.New("image dimensions exceed the limit")
+	ErrTooLong        = errors.New("audio duration exceeds the limit")
+	ErrSilent         = errors.New("audio is silent")
+	ErrNoDecoder      = errors.New("no decoder for the audio format")
+)
+
+// Info describes a file whose format has been detected and checked
+type Info struct {
+	MIME     string
+	Ext      string // canonical extension for MIME, e.g. ".jpg"
+	Kind     string
+	Width    int     // images only
+	Height   int     // images only
+	Duration float64 // audio only, seconds
+
+	exif []byte // TIFF-encoded EXIF block, if the image carries one
+}
+
+type format struct {
+	mime  string
+	ext   string
+	kind  string
+	match func(data []byte) bool
+	parse func(data []byte, info *Info) error
+}
+
+var formats = []format{
+	{MIMEJPEG, ".jpg", KindImage, isJPEG, parseJPEG},
+	{MIMEPNG, ".png", KindImage, isPNG, parsePNG},
+	{MIMEWebP, ".webp", KindImage, isWebP, parseWebP},
+	{MIMEWAV, ".wav", KindAudio, isWAV, parseWAV},
+	{MIMEWebM, ".webm", KindAudio, isWebM, parseWebM},
+	{MIMEMP3, ".mp3", KindAudio, isMP3, parseMP3},
+}
+
+// TypeByExtension returns the MIME type of a detected format's canonical
+// extension, e.g. ".webp", or "" for any other extension
+func TypeByExtension(ext string) string {
+	for _, f := range formats {
+		if f.ext == ext {
+			return f.mime
+		}
+	}
+	return ""
+}
+
+// markupSignatures are byte sequences that let a media file double as a page
+// or script when a browser or server sniffs it. Compared case-insensitively.
+var markupSignatures = [][]byte{
+	[]byte("<script"),
+	[]byte("<html"),
+	[]byte("<!doctype"),
+	[]byte("<?php"),
+	[]byte("<iframe"),
+	[]byte("javascript:"),
+}
+
+// Detect identifies data by its magic bytes, then walks its structure to make
+// sure it is complete and that nothing is appended to or hidden inside it
+func Detect(data []byte) (*Info, error) {
+	for _, f := range formats {
+		if !f.match(data) {
+			continue
+		}
+		info := &Info{MIME: f.mime, Ext: f.ext, Kind: f.kind}
+		if err := f.parse(data, info); err != nil {
+			return nil, err
+		}
+		if err := checkMarkup(data); err != nil {
+			return nil, err
+		}
+		return info, nil
+	}
+	return nil, ErrUnsupported
+}
+
+// Validate detects data and checks it is of the wanted kind and, for images,
+// that neither side exceeds maxDimension pixels (0 means no limit)
+func Validate(data []byte, kind string, maxDimension int) (*Info, error) {
+	info, err := Detect(data)
+	if err != nil {
+		return nil, err
+	}
+	if info.Kind != kind {
+		return nil, fmt.Errorf("%w: found %s", ErrWrongKind, info.MIME)
+	}
+	if maxDimension > 0 && (info.Width > maxDimension || info.Height > maxDimension) {
+		return nil, fmt.Errorf("%w: %dx%d is larger than %dx%d",
+			ErrTooLarge, info.Width, info.Height, maxDimension, maxDimension)
+	}
+	return info, nil
+}
+
+func checkMarkup(data []byte) error {
+	lower := bytes.ToLower(data)
+	for _, sig := range markupSignatures {
+		if bytes.Contains(lower, sig) {
+			return ErrEmbeddedMarkup
+		}
+	}
+	return nil
+}
+
+// trailing reports whether anything other than zero padding follows end
+func trailing(data []byte, end int) bool {
+	for _, b := range data[end:] {
+		if b != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func malformed(format, reason string) error {
+	return fmt.Errorf("%w: %s: %s", ErrMalformed, format, reason)
+}
@@ -0,0 +1,261 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		mime     string
+		width    int
+		height   int
+		duration float64
+		err      error
+	}{
+		{name: "jpeg", data: testJPEG(t, 40, 30), mime: MIMEJPEG, width: 40, height: 30},
+		{name: "png", data: testPNG(t, 16, 9), mime: MIMEPNG, width: 16, height: 9},
+		{name: "webp lossless", data: testWebP(3, 2), mime: MIMEWebP, width: 3, height: 2},
+		{name: "wav", data: testWAV(8000, 8000), mime: MIMEWAV, duration: 1},
+		{name: "mp3", data: testMP3(50), mime: MIMEMP3, duration: 50 * 1152.0 / 44100},
+		{name: "mp3 with id3v1 tag", data: append(testMP3(2), testID3v1()...), mime: MIMEMP3, duration: 2 * 1152.0 / 44100},
+		{name: "webm", data: testWebM(1500), mime: MIMEWebM, duration: 1.5},
+
+		{name: "empty", data: nil, err: ErrUnsupported},
+		{name: "text", data: []byte("hello, world"), err: ErrUnsupported},
+		{name: "gif", data: []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), err: ErrUnsupported},
+		{name: "truncated png", data: truncate(testPNG(t, 16, 9), 10), err: ErrTruncated},
+		{name: "png with trailing data", data: append(testPNG(t, 16, 9), "extra"...), err: ErrTrailingData},
+		{name: "png with zero padding", data: append(testPNG(t, 16, 9), 0, 0, 0), mime: MIMEPNG, width: 16, height: 9},
+		{name: "truncated wav", data: truncate(testWAV(100, 8000), 20), err: ErrTruncated},
+		{name: "wav with trailing data", data: append(testWAV(100, 8000), "junk"...), err: ErrTrailingData},
+		{name: "wav with markup", data: withMarkup(testWAV(100, 8000)), err: ErrEmbeddedMarkup},
+		{name: "truncated mp3", data: truncate(testMP3(3), 100), err: ErrTruncated},
+		{name: "id3 tag without frames", data: testID3v2(), err: ErrMalformed},
+		{name: "webm with wrong doctype", data: testEBML("matroska", 1500), err: ErrMalformed},
+		{name: "webp without image", data: testRIFF("WEBP", "ICCP", make([]byte, 4)), err: ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Detect(tt.data)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Detect() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			if info.MIME != tt.mime {
+				t.Errorf("MIME = %q, want %q", info.MIME, tt.mime)
+			}
+			if info.Width != tt.width || info.Height != tt.height {
+				t.Errorf("size = %dx%d, want %dx%d", info.Width, info.Height, tt.width, tt.height)
+			}
+			if math.Abs(info.Duration-tt.duration) > 0.001 {
+				t.Errorf("Duration = %v, want %v", info.Duration, tt.duration)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   []byte
+		kind   string
+		maxDim int
+		err    error
+	}{
+		{name: "image", data: testPNG(t, 16, 9), kind: KindImage, maxDim: 16},
+		{name: "no dimension limit", data: testPNG(t, 16, 9), kind: KindImage},
+		{name: "audio", data: testMP3(2), kind: KindAudio, maxDim: 16},
+		{name: "image too wide", data: testPNG(t, 17, 9), kind: KindImage, maxDim: 16, err: ErrTooLarge},
+		{name: "image too tall", data: testJPEG(t, 8, 20), kind: KindImage, maxDim: 16, err: ErrTooLarge},
+		{name: "audio as image", data: testMP3(2), kind: KindImage, err: ErrWrongKind},
+		{name: "image as audio", data: testWebP(3, 2), kind: KindAudio, err: ErrWrongKind},
+		{name: "unsupported", data: []byte("%PDF-1.4"), kind: KindImage, err: ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Validate(tt.data, tt.kind, tt.maxDim)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Validate() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestProcessAudio(t *testing.T) {
+	const rate = 8000
+	// Half a second of silence either side of a one second tone
+	tone := make([]float64, 2*rate)
+	for i := rate / 2; i < 3*rate/2; i++ {
+		tone[i] = 0.25 * math.Sin(2*math.Pi*440*float64(i)/rate)
+	}
+	clip := encodeWAV(&pcm{samples: tone, sampleRate: rate})
+
+	tests := []struct {
+		name        string
+		data        []byte
+		maxDuration time.Duration
+		duration    float64
+		err         error
+	}{
+		{name: "silence trimmed", data: clip, duration: 1 + 2*silencePadding},
+		{name: "within limit", data: clip, maxDuration: 2 * time.Second, duration: 1 + 2*silencePadding},
+		{name: "too long once trimmed", data: clip, maxDuration: time.Second, err: ErrTooLong},
+		{name: "silent", data: testWAV(rate, rate), err: ErrSilent},
+		{name: "mp3 without decoder", data: testMP3(10), err: ErrNoDecoder},
+		{name: "webm without decoder", data: testWebM(1500), err: ErrNoDecoder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Detect(tt.data)
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			data, out, err := ProcessAudio(tt.data, info, tt.maxDuration, nil)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("ProcessAudio() error = %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ProcessAudio() error = %v", err)
+			}
+			if out.MIME != MIMEWAV || out.Ext != ".wav" {
+				t.Errorf("format = %s %s, want %s .wav", out.MIME, out.Ext, MIMEWAV)
+			}
+			if math.Abs(out.Duration-tt.duration) > 0.02 {
+				t.Errorf("Duration = %v, want %v", out.Duration, tt.duration)
+			}
+			if _, err := Detect(data); err != nil {
+				t.Errorf("processed clip does not detect: %v", err)
+			}
+		})
+	}
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testWAV builds a silent 16-bit mono PCM WAV file
+func testWAV(samples, rate int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+2*samples))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, struct {
+		Size             uint32
+		Format, Channels uint16
+		Rate, ByteRate   uint32
+		Align, Bits      uint16
+	}{16, 1, 1, uint32(rate), uint32(2 * rate), 2, 16})
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(2*samples))
+	buf.Write(make([]byte, 2*samples))
+	return buf.Bytes()
+}
+
+// testRIFF builds a RIFF file of the given form holding one chunk
+func testRIFF(form, id string, body []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(4+8+len(body)+len(body)%2))
+	buf.WriteString(form)
+	buf.WriteString(id)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(body)))
+	buf.Write(body)
+	if len(body)%2 == 1 {
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+// testWebP builds a lossless WebP whose VP8L header declares the size; the
+// bitstream after it is not decoded
+func testWebP(width, height int) []byte {
+	body := make([]byte, 9)
+	body[0] = 0x2f
+	binary.LittleEndian.PutUint32(body[1:], uint32(width-1)|uint32(height-1)<<14)
+	return testRIFF("WEBP", "VP8L", body)
+}
+
+// testMP3 builds frames of silent 128kbps 44.1kHz MPEG-1 layer III audio
+func testMP3(frames int) []byte {
+	frame := make([]byte, 144*128000/44100)
+	copy(frame, []byte{0xff, 0xfb, 0x90, 0x00})
+	return bytes.Repeat(frame, frames)
+}
+
+func testID3v1() []byte {
+	tag := make([]byte, 128)
+	copy(tag, "TAG")
+	return tag
+}
+
+// testID3v2 builds an empty ID3v2 tag
+func testID3v2() []byte {
+	return append([]byte("ID3\x04\x00\x00\x00\x00\x00\x0a"), make([]byte, 10)...)
+}
+
+func testWebM(durationMs float64) []byte {
+	return testEBML("webm", durationMs)
+}
+
+// testEBML builds an EBML file of the given doctype whose segment holds only
+// an info element declaring the duration, in the default timecode scale
+func testEBML(docType string, durationMs float64) []byte {
+	duration := make([]byte, 8)
+	binary.BigEndian.PutUint64(duration, math.Float64bits(durationMs))
+	return append(
+		ebml([]byte{0x1a, 0x45, 0xdf, 0xa3}, ebml([]byte{0x42, 0x82}, []byte(docType))),
+		ebml([]byte{0x18, 0x53, 0x80, 0x67}, ebml([]byte{0x15, 0x49, 0xa9, 0x66}, ebml([]byte{0x44, 0x89}, duration)))...,
+	)
+}
+
+// ebml encodes an element with a one byte size
+func ebml(id, body []byte) []byte {
+	element := append(append([]byte{}, id...), 0x80|byte(len(body)))
+	return append(element, body...)
+}
+
+func truncate(data []byte, n int) []byte {
+	return data[:len(data)-n]
+}
+
+// withMarkup writes a script tag over the start of a WAV file's samples
+func withMarkup(wav []byte) []byte {
+	copy(wav[44:], "<script>")
+	return wav
+}
@@ -0,0 +1,237 @@
+package media
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Silence trimming: the clip is cut to the first and last 10ms windows within
+// silenceRange dB of the loudest one, never below the absolute floor, keeping
+// silencePadding either side so soft onsets and endings survive
+const (
+	silenceWindow  = 0.010 // seconds
+	silenceRange   = 35.0  // dB
+	silenceFloor   = -60.0 // dBFS
+	silencePadding = 0.100 // seconds
+)
+
+// Loudness normalization, measured as in ITU-R BS.1770: K-weighted, over
+// 400ms blocks overlapping by 75%, gated at -70 LUFS and then at 10 LU below
+// the mean of the blocks left
+const (
+	targetLoudness = -16.0 // LUFS
+	peakCeiling    = -1.0  // dBFS
+	loudnessBlock  = 0.400 // seconds
+	loudnessStep   = 0.100 // seconds
+	absoluteGate   = -70.0 // LUFS
+	relativeGate   = -10.0 // LU
+)
+
+// ProcessAudio prepares a validated clip for serving. It is decoded, trimmed
+// of leading and trailing silence, normalized to targetLoudness and re-encoded
+// as 16-bit mono WAV, whatever format it was uploaded in. PCM WAV is decoded
+// here; MP3, WebM and other WAV encodings need the decoder, and are rejected
+// with ErrNoDecoder when it is nil. A clip longer than maxDuration (0 means
+// no limit) once processed is rejected.
+func ProcessAudio(data []byte, info *Info, maxDuration time.Duration, decoder *FFmpeg) ([]byte, *Info, error) {
+	var p *pcm
+	if info.MIME == MIMEWAV {
+		decoded, ok, err := decodeWAV(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			p = decoded
+		}
+	}
+	if p == nil {
+		if decoder == nil {
+			return nil, nil, fmt.Errorf("%w: %s", ErrNoDecoder, info.MIME)
+		}
+		decoded, err := decoder.decode(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		p = decoded
+	}
+
+	if err := normalize(trimSilence(p)); err != nil {
+		return nil, nil, err
+	}
+	out := *info
+	out.MIME, out.Ext = MIMEWAV, ".wav"
+	out.Duration = p.seconds()
+	if maxDuration > 0 && out.Duration > maxDuration.Seconds() {
+		return nil, nil, fmt.Errorf("%w: %.1fs is longer than %s", ErrTooLong, out.Duration, maxDuration)
+	}
+	return encodeWAV(p), &out, nil
+}
+
+// trimSilence cuts p down to its audible part, in place
+func trimSilence(p *pcm) *pcm {
+	window := max(1, int(silenceWindow*float64(p.sampleRate)))
+	levels := make([]float64, 0, len(p.samples)/window+1)
+	loudest := math.Inf(-1)
+	for start := 0; start < len(p.samples); start += window {
+		level := decibels(meanSquare(p.samples[start:min(start+window, len(p.samples))]))
+		levels = append(levels, level)
+		loudest = max(loudest, level)
+	}
+
+	threshold := max(silenceFloor, loudest-silenceRange)
+	first, last := -1, -1
+	for i, level := range levels {
+		if level >= threshold {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		p.samples = p.samples[:0]
+		return p
+	}
+
+	padding := int(silencePadding * float64(p.sampleRate))
+	start := max(0, first*window-padding)
+	end := min(len(p.samples), (last+1)*window+padding)
+	p.samples = p.samples[start:end]
+	return p
+}
+
+// normalize applies the gain that brings p to targetLoudness, reduced if
+// needed to keep the peak under peakCeiling
+func normalize(p *pcm) error {
+	peak := 0.0
+	for _, s := range p.samples {
+		peak = max(peak, math.Abs(s))
+	}
+	loudness, ok := integratedLoudness(p)
+	if peak == 0 || !ok {
+		return ErrSilent
+	}
+
+	gain := math.Min(
+		math.Pow(10, (targetLoudness-loudness)/20),
+		math.Pow(10, peakCeiling/20)/peak,
+	)
+	for i := range p.samples {
+		p.samples[i] *= gain
+	}
+	return nil
+}
+
+// integratedLoudness measures p in LUFS. It reports false when every block is
+// below the absolute gate.
+func integratedLoudness(p *pcm) (float64, bool) {
+	weighted := kWeight(p)
+	block := int(loudnessBlock * float64(p.sampleRate))
+	step := int(loudnessStep * float64(p.sampleRate))
+
+	var powers []float64
+	if len(weighted) < block {
+		powers = append(powers, meanSquare(weighted))
+	}
+	for start := 0; start+block <= len(weighted); start += step {
+		powers = append(powers, meanSquare(weighted[start:start+block]))
+	}
+
+	gated := func(threshold float64) (float64, int) {
+		sum, n := 0.0, 0
+		for _, power := range powers {
+			if lufs(power) > threshold {
+				sum += power
+				n++
+			}
+		}
+		if n == 0 {
+			return 0, 0
+		}
+		return sum / float64(n), n
+	}
+
+	mean, n := gated(absoluteGate)
+	if n == 0 {
+		return 0, false
+	}
+	mean, n = gated(lufs(mean) + relativeGate)
+	if n == 0 {
+		return 0, false
+	}
+	return lufs(mean), true
+}
+
+// kWeight applies the BS.1770 pre-filter, a high shelf of +4dB from 1.5kHz
+// modelling the head, then a high-pass at 38Hz. The coefficients are derived
+// for p's sample rate rather than the 48kHz constants the standard lists.
+func kWeight(p *pcm) []float64 {
+	rate := float64(p.sampleRate)
+
+	// High shelf
+	a := math.Pow(10, 4.0/40)
+	w := 2 * math.Pi * 1500 / rate
+	alpha := math.Sin(w) / (2 / math.Sqrt2)
+	cos, sqrtA := math.Cos(w), math.Sqrt(a)
+	shelf := biquad{
+		b0: a * ((a + 1) + (a-1)*cos + 2*sqrtA*alpha),
+		b1: -2 * a * ((a - 1) + (a+1)*cos),
+		b2: a * ((a + 1) + (a-1)*cos - 2*sqrtA*alpha),
+		a0: (a + 1) - (a-1)*cos + 2*sqrtA*alpha,
+		a1: 2 * ((a - 1) - (a+1)*cos),
+		a2: (a + 1) - (a-1)*cos - 2*sqrtA*alpha,
+	}
+
+	// High-pass
+	w = 2 * math.Pi * 38 / rate
+	alpha = math.Sin(w) / (2 * 0.5)
+	cos = math.Cos(w)
+	highPass := biquad{
+		b0: (1 + cos) / 2,
+		b1: -(1 + cos),
+		b2: (1 + cos) / 2,
+		a0: 1 + alpha,
+		a1: -2 * cos,
+		a2: 1 - alpha,
+	}
+
+	return highPass.apply(shelf.apply(p.samples))
+}
+
+// biquad is a second-order IIR filter with unnormalized coefficients
+type biquad struct {
+	b0, b1, b2, a0, a1, a2 float64
+}
+
+func (f biquad) apply(in []float64) []float64 {
+	out := make([]float64, len(in))
+	var x1, x2, y1, y2 float64
+	for i, x := range in {
+		y := (f.b0*x + f.b1*x1 + f.b2*x2 - f.a1*y1 - f.a2*y2) / f.a0
+		x2, x1 = x1, x
+		y2, y1 = y1, y
+		out[i] = y
+	}
+	return out
+}
+
+func meanSquare(samples []float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, s := range samples {
+		sum += s * s
+	}
+	return sum / float64(len(samples))
+}
+
+func decibels(power float64) float64 {
+	return 10 * math.Log10(power)
+}
+
+// lufs converts the mean square of K-weighted samples to loudness
+func lufs(power float64) float64 {
+	return -0.691 + decibels(power)
+}
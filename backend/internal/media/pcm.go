@@ -0,0 +1,129 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// WAV sample formats, from the fmt chunk or the subformat of an extensible one
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xfffe
+)
+
+// pcm is decoded audio: mono samples in [-1, 1]
+type pcm struct {
+	samples    []float64
+	sampleRate int
+}
+
+func (p *pcm) seconds() float64 {
+	return float64(len(p.samples)) / float64(p.sampleRate)
+}
+
+// decodeWAV decodes integer PCM of 8 to 32 bits and 32 or 64 bit float
+// samples, mixing the channels down to mono. It reports false for any other
+// encoding, such as ADPCM.
+func decodeWAV(data []byte) (*pcm, bool, error) {
+	chunks, err := riffChunks(data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var format, channels, bits, blockAlign, sampleRate int
+	var body []byte
+	for _, chunk := range chunks {
+		switch chunk.id {
+		case "fmt ":
+			if len(chunk.body) < 16 {
+				return nil, false, malformed("wav", "short fmt chunk")
+			}
+			format = int(binary.LittleEndian.Uint16(chunk.body[0:2]))
+			channels = int(binary.LittleEndian.Uint16(chunk.body[2:4]))
+			sampleRate = int(binary.LittleEndian.Uint32(chunk.body[4:8]))
+			blockAlign = int(binary.LittleEndian.Uint16(chunk.body[12:14]))
+			bits = int(binary.LittleEndian.Uint16(chunk.body[14:16]))
+			if format == wavFormatExtensible && len(chunk.body) >= 26 {
+				format = int(binary.LittleEndian.Uint16(chunk.body[24:26]))
+			}
+		case "data":
+			body = append(body, chunk.body...)
+		}
+	}
+
+	bytesPerSample := bits / 8
+	switch {
+	case format == wavFormatPCM && bits%8 == 0 && bytesPerSample >= 1 && bytesPerSample <= 4:
+	case format == wavFormatFloat && (bits == 32 || bits == 64):
+	default:
+		return nil, false, nil
+	}
+	if channels == 0 || sampleRate == 0 || blockAlign < channels*bytesPerSample {
+		return nil, false, malformed("wav", "invalid fmt chunk")
+	}
+
+	frames := len(body) / blockAlign
+	out := &pcm{samples: make([]float64, frames), sampleRate: sampleRate}
+	for i := range frames {
+		frame := body[i*blockAlign:]
+		sum := 0.0
+		for ch := range channels {
+			sum += wavSample(frame[ch*bytesPerSample:], format, bytesPerSample)
+		}
+		out.samples[i] = sum / float64(channels)
+	}
+	return out, true, nil
+}
+
+// wavSample decodes one little-endian sample. 8-bit PCM is unsigned; wider
+// PCM is signed.
+func wavSample(b []byte, format, size int) float64 {
+	if format == wavFormatFloat {
+		if size == 4 {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b))
+	}
+	if size == 1 {
+		return (float64(b[0]) - 128) / 128
+	}
+	var v int32
+	for i := size - 1; i >= 0; i-- {
+		v = v<<8 | int32(b[i])
+	}
+	shift := 32 - 8*size
+	v = v << shift >> shift // sign-extend
+	return float64(v) / float64(int64(1)<<(8*size-1))
+}
+
+// EncodeWAV writes mono samples in [-1, 1] as a 16-bit PCM WAV file
+func EncodeWAV(samples []float64, sampleRate int) []byte {
+	return encodeWAV(&pcm{samples: samples, sampleRate: sampleRate})
+}
+
+// encodeWAV writes 16-bit mono PCM
+func encodeWAV(p *pcm) []byte {
+	dataSize := 2 * len(p.samples)
+	var buf bytes.Buffer
+	buf.Grow(44 + dataSize)
+
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVEfmt ")
+	binary.Write(&buf, binary.LittleEndian, []uint32{16})
+	binary.Write(&buf, binary.LittleEndian, []uint16{wavFormatPCM, 1})
+	binary.Write(&buf, binary.LittleEndian, []uint32{uint32(p.sampleRate), uint32(2 * p.sampleRate)})
+	binary.Write(&buf, binary.LittleEndian, []uint16{2, 16})
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+
+	sample := make([]byte, 2)
+	for _, s := range p.samples {
+		v := math.Round(max(-1, min(1, s)) * math.MaxInt16)
+		binary.LittleEndian.PutUint16(sample, uint16(int16(v)))
+		buf.Write(sample)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,36 @@
+package media
+
+import "encoding/binary"
+
+type riffChunk struct {
+	id   string
+	body []byte
+}
+
+// riffChunks checks the RIFF header's size matches the file and splits the
+// form into its chunks
+func riffChunks(data []byte) ([]riffChunk, error) {
+	end := 8 + int(binary.LittleEndian.Uint32(data[4:8]))
+	if end > len(data) {
+		return nil, ErrTruncated
+	}
+	if trailing(data, end) {
+		return nil, ErrTrailingData
+	}
+
+	var chunks []riffChunk
+	pos := 12
+	for pos < end {
+		if pos+8 > end {
+			return nil, ErrTruncated
+		}
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		bodyEnd := pos + 8 + size
+		if bodyEnd > end || bodyEnd < pos {
+			return nil, ErrTruncated
+		}
+		chunks = append(chunks, riffChunk{id: string(data[pos : pos+4]), body: data[pos+8 : bodyEnd]})
+		pos = bodyEnd + size%2 // chunks are padded to an even size
+	}
+	return chunks, nil
+}
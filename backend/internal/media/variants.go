@@ -0,0 +1,205 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"path"
+	"strings"
+
+	"github.com/chai2010/webp"
+	"golang.org/x/image/draw"
+)
+
+// Image variant names. Each fits within its size on both sides.
+const (
+	VariantThumbnail = "thumbnail"
+	VariantCard      = "card"
+	VariantFull      = "full"
+)
+
+var imageVariants = []struct {
+	name    string
+	maxSide int
+}{
+	{VariantThumbnail, 160},
+	{VariantCard, 480},
+	{VariantFull, 1280},
+}
+
+const (
+	jpegQuality = 85
+	webpQuality = 80
+)
+
+// Variant is one resized, re-encoded copy of an uploaded image
+type Variant struct {
+	Name   string
+	MIME   string
+	Ext    string
+	Width  int
+	Height int
+	Data   []byte
+}
+
+// Filename is the variant's name within its image set directory
+func (v Variant) Filename() string {
+	return v.Name + v.Ext
+}
+
+// ProcessImage decodes a validated image, turns it upright according to its
+// EXIF orientation and encodes every variant size in the original format and
+// in WebP. Re-encoding drops EXIF and all other metadata. Images are never
+// scaled up, so a small image yields variants of its own size.
+func ProcessImage(data []byte, info *Info) ([]Variant, error) {
+	var (
+		img image.Image
+		err error
+	)
+	switch info.MIME {
+	case MIMEJPEG:
+		img, err = jpeg.Decode(bytes.NewReader(data))
+	case MIMEPNG:
+		img, err = png.Decode(bytes.NewReader(data))
+	case MIMEWebP:
+		img, err = webp.Decode(bytes.NewReader(data))
+	default:
+		return nil, fmt.Errorf("%w: %s is not an image", ErrWrongKind, info.MIME)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	upright := orient(img, exifOrientation(info.exif))
+
+	var variants []Variant
+	for _, size := range imageVariants {
+		resized := fit(upright, size.maxSide)
+		b := resized.Bounds()
+
+		encoded, err := encodeImage(resized, info.MIME)
+		if err != nil {
+			return nil, err
+		}
+		variants = append(variants, Variant{
+			Name: size.name, MIME: info.MIME, Ext: info.Ext,
+			Width: b.Dx(), Height: b.Dy(), Data: encoded,
+		})
+
+		if info.MIME != MIMEWebP {
+			encoded, err := encodeImage(resized, MIMEWebP)
+			if err != nil {
+				return nil, err
+			}
+			variants = append(variants, Variant{
+				Name: size.name, MIME: MIMEWebP, Ext: ".webp",
+				Width: b.Dx(), Height: b.Dy(), Data: encoded,
+			})
+		}
+	}
+	return variants, nil
+}
+
+// fit scales img down so neither side exceeds maxSide, keeping its aspect ratio
+func fit(img *image.NRGBA, maxSide int) *image.NRGBA {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+	if w >= h {
+		h = max(1, h*maxSide/w)
+		w = maxSide
+	} else {
+		w = max(1, w*maxSide/h)
+		h = maxSide
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+func encodeImage(img *image.NRGBA, mime string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch mime {
+	case MIMEJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case MIMEPNG:
+		err = png.Encode(&buf, img)
+	case MIMEWebP:
+		err = webp.Encode(&buf, img, &webp.Options{Quality: webpQuality})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ImageSource holds the URLs of one variant size
+type ImageSource struct {
+	WebP     string `json:"webp"`
+	Original string `json:"original"`
+}
+
+// ImageSet is the responsive set of an image uploaded with variants
+type ImageSet struct {
+	Thumbnail ImageSource `json:"thumbnail"`
+	Card      ImageSource `json:"card"`
+	Full      ImageSource `json:"full"`
+}
+
+// Map returns a copy of the set with fn applied to every URL. It returns nil
+// for a nil set.
+func (s *ImageSet) Map(fn func(string) string) *ImageSet {
+	if s == nil {
+		return nil
+	}
+	source := func(src ImageSource) ImageSource {
+		return ImageSource{WebP: fn(src.WebP), Original: fn(src.Original)}
+	}
+	return &ImageSet{Thumbnail: source(s.Thumbnail), Card: source(s.Card), Full: source(s.Full)}
+}
+
+// ImageSetFor derives the variant URLs from the URL of an image's full
+// variant, e.g. /uploads/images/<id>/full.jpg, wherever the images are
+// served from. It returns nil for any other URL, such as an image uploaded
+// before variants were generated.
+func ImageSetFor(url *string) *ImageSet {
+	if url == nil {
+		return nil
+	}
+	dir, file := path.Split(*url)
+	parent, id, ok := cutLast(strings.TrimSuffix(dir, "/"), "/")
+	if !ok || id == "" || !strings.HasSuffix(parent, "/images") {
+		return nil
+	}
+	ext := path.Ext(file)
+	if strings.TrimSuffix(file, ext) != VariantFull {
+		return nil
+	}
+	switch ext {
+	case ".jpg", ".png", ".webp":
+	default:
+		return nil
+	}
+
+	source := func(name string) ImageSource {
+		return ImageSource{WebP: dir + name + ".webp", Original: dir + name + ext}
+	}
+	return &ImageSet{
+		Thumbnail: source(VariantThumbnail),
+		Card:      source(VariantCard),
+		Full:      source(VariantFull),
+	}
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
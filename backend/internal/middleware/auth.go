@@ -8,50 +8,65 @@ import (
 	"github.com/learng/backend/internal/utils"
 )
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware(jwtSecret string) echo.MiddlewareFunc {
+// SessionChecker reports whether a server-side session is still active
+type SessionChecker interface {
+	IsSessionActive(sessionID string) (bool, error)
+}
+
+// AuthMiddleware validates JWT tokens and rejects tokens whose session has
+// been revoked
+func AuthMiddleware(jwtSecret string, sessions SessionChecker) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" {
-				return c.JSON(http.StatusUnauthorized, map[string]string{
-					"error": "Missing authorization header",
-				})
+				return c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Missing authorization header"))
 			}
 
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 			if tokenString == authHeader {
-				return c.JSON(http.StatusUnauthorized, map[string]string{
-					"error": "Invalid authorization header format",
-				})
+				return c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Invalid authorization header format"))
 			}
 
-			claims, err := utils.ValidateToken(tokenString, jwtSecret)
-			if err != nil {
-				return c.JSON(http.StatusUnauthorized, map[string]string{
-					"error": "Invalid or expired token",
-				})
+			if status, message := authenticate(c, tokenString, jwtSecret, sessions); status != 0 {
+				return c.JSON(status, utils.ErrorResponse(message))
 			}
 
-			// Store user info in context
-			c.Set("userId", claims.UserID)
-			c.Set("userEmail", claims.Email)
-			c.Set("userRole", claims.Role)
-
 			return next(c)
 		}
 	}
 }
 
+// authenticate validates an access token and stores its user in the context.
+// On failure it returns the status and message to respond with.
+func authenticate(c echo.Context, tokenString, jwtSecret string, sessions SessionChecker) (int, string) {
+	claims, err := utils.ValidateToken(tokenString, jwtSecret)
+	if err != nil || claims.SessionID == "" {
+		return http.StatusUnauthorized, "Invalid or expired token"
+	}
+	active, err := sessions.IsSessionActive(claims.SessionID)
+	if err != nil {
+		return http.StatusInternalServerError, "Failed to verify session"
+	}
+	if !active {
+		return http.StatusUnauthorized, "Session has been revoked"
+	}
+
+	// Store user info in context
+	c.Set("userId", claims.UserID)
+	c.Set("userEmail", claims.Email)
+	c.Set("userRole", claims.Role)
+	c.Set("sessionId", claims.SessionID)
+	return 0, ""
+}
+
 // RequireRole ensures the user has a specific role
 func RequireRole(role string) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			userRole := c.Get("userRole")
 			if userRole == nil || userRole != role {
-				return c.JSON(http.StatusForbidden, map[string]string{
-					"error": "Insufficient permissions",
-				})
+				return c.JSON(http.StatusForbidden, utils.ErrorResponse("Insufficient permissions"))
 			}
 			return next(c)
 		}
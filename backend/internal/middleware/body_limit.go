@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/utils"
+)
+
+// BodyLimit rejects a request whose declared length exceeds maxBytes and
+// stops reading a body that grows past it while streaming, so an oversized
+// upload is never buffered in full
+func BodyLimit(maxBytes int64) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if req.ContentLength > maxBytes {
+				return c.JSON(http.StatusRequestEntityTooLarge, utils.ErrorResponse(
+					fmt.Sprintf("Request too large (max %s)", utils.FormatSize(maxBytes)),
+				))
+			}
+			req.Body = http.MaxBytesReader(c.Response(), req.Body, maxBytes)
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/learng/backend/internal/storage"
+	"github.com/learng/backend/internal/utils"
+)
+
+// MediaAuth guards stored media. A request with a sig query parameter must
+// carry a valid, unexpired signature for its key; its expiry is stored in the
+// context as mediaExpiresAt. Any other request needs an access token in the
+// Authorization header. Tokens are never taken from the query string, where
+// they would end up in logs, browser history and Referer headers; clients
+// that cannot set headers, such as image and audio elements, use signed URLs.
+func MediaAuth(jwtSecret string, sessions SessionChecker, signer *storage.URLSigner) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			query := c.QueryParams()
+			if query.Has("sig") {
+				expiresAt, err := signer.Verify(c.Param("*"), query.Get("expires"), query.Get("sig"))
+				if err != nil {
+					if errors.Is(err, storage.ErrSignatureExpired) {
+						return c.JSON(http.StatusForbidden, utils.ErrorResponse("Media URL has expired"))
+					}
+					return c.JSON(http.StatusForbidden, utils.ErrorResponse("Invalid media URL signature"))
+				}
+				c.Set("mediaExpiresAt", expiresAt)
+				return next(c)
+			}
+
+			tokenString := strings.TrimPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+			if tokenString == "" {
+				return c.JSON(http.StatusUnauthorized, utils.ErrorResponse("Missing access token or signature"))
+			}
+			if status, message := authenticate(c, tokenString, jwtSecret, sessions); status != 0 {
+				return c.JSON(status, utils.ErrorResponse(message))
+			}
+			return next(c)
+		}
+	}
+}
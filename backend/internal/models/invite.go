@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Invite is a registration code created by an admin. Only the hash of the code
+// is stored; the code itself is shown once at creation.
+type Invite struct {
+	ID        string     `gorm:"primaryKey" json:"id"`
+	CodeHash  string     `gorm:"not null;uniqueIndex" json:"-"`
+	Role      string     `gorm:"not null" json:"role"` // Role granted to users registering with this code
+	MaxUses   int        `gorm:"not null;default:1" json:"maxUses"`
+	UseCount  int        `gorm:"not null;default:0" json:"useCount"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt"`
+	CreatedBy string     `gorm:"not null;index" json:"createdBy"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+
+	// Associations
+	Creator User `gorm:"foreignKey:CreatedBy" json:"-"`
+}
+
+func (i *Invite) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == "" {
+		i.ID = uuid.New().String()
+	}
+	if i.MaxUses == 0 {
+		i.MaxUses = 1
+	}
+	return nil
+}
+
+func (Invite) TableName() string {
+	return "invites"
+}
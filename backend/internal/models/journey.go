@@ -8,16 +8,20 @@ import (
 )
 
 type Journey struct {
-	ID             string         `gorm:"primaryKey" json:"id"`
-	Title          string         `gorm:"not null" json:"title"`
-	Description    string         `json:"description"`
-	SourceLanguage string         `gorm:"not null" json:"sourceLanguage"`       // ISO 639-1 code
-	TargetLanguage string         `gorm:"not null" json:"targetLanguage"`       // ISO 639-1 code
-	Status         string         `gorm:"not null;default:draft" json:"status"` // 'draft' | 'published' | 'archived'
-	CreatedBy      string         `gorm:"not null" json:"createdBy"`
-	CreatedAt      time.Time      `json:"createdAt"`
-	UpdatedAt      time.Time      `json:"updatedAt"`
-	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              string         `gorm:"primaryKey" json:"id"`
+	Title           string         `gorm:"not null" json:"title"`
+	Description     string         `json:"description"`
+	SourceLanguage  string         `gorm:"not null" json:"sourceLanguage"`       // ISO 639-1 code
+	TargetLanguage  string         `gorm:"not null" json:"targetLanguage"`       // ISO 639-1 code
+	Status          string         `gorm:"not null;default:draft" json:"status"` // 'draft' | 'scheduled' | 'published' | 'archived'
+	PublishAt       *time.Time     `gorm:"index" json:"publishAt"`               // Release time while scheduled
+	PublishedAt     *time.Time     `json:"publishedAt"`
+	LiveVersionID   *string        `json:"liveVersionId"` // Version learners start on
+	CreatedBy       string         `gorm:"not null" json:"createdBy"`
+	SourceJourneyID *string        `gorm:"index" json:"sourceJourneyId"` // Journey this one was cloned from
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Associations
 	Creator   User       `gorm:"foreignKey:CreatedBy" json:"-"`
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JourneyVersion is an immutable snapshot of a journey's full tree taken when
+// it is published. Learners read from versions rather than the editable rows.
+type JourneyVersion struct {
+	ID             string    `gorm:"primaryKey" json:"id"`
+	JourneyID      string    `gorm:"not null;uniqueIndex:idx_journey_version" json:"journeyId"`
+	Version        int       `gorm:"not null;uniqueIndex:idx_journey_version" json:"version"`
+	Snapshot       string    `gorm:"type:text;not null" json:"-"` // JSON tree of scenarios, words and quizzes
+	PublishedBy    string    `gorm:"not null" json:"publishedBy"`
+	RestoredFromID *string   `json:"restoredFromId"` // Version a rollback copied
+	CreatedAt      time.Time `json:"createdAt"`
+
+	// Associations
+	Journey Journey `gorm:"foreignKey:JourneyID" json:"-"`
+}
+
+func (jv *JourneyVersion) BeforeCreate(tx *gorm.DB) error {
+	if jv.ID == "" {
+		jv.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// BeforeUpdate rejects every update; a published version never changes
+func (jv *JourneyVersion) BeforeUpdate(tx *gorm.DB) error {
+	return errors.New("journey versions are immutable")
+}
+
+func (JourneyVersion) TableName() string {
+	return "journey_versions"
+}
+
+// LearnerJourneyPin records which version of a journey a learner is working
+// through, so edits published later do not change a lesson mid-way
+type LearnerJourneyPin struct {
+	ID        string    `gorm:"primaryKey" json:"id"`
+	UserID    string    `gorm:"not null;uniqueIndex:idx_user_journey" json:"userId"`
+	JourneyID string    `gorm:"not null;uniqueIndex:idx_user_journey" json:"journeyId"`
+	VersionID string    `gorm:"not null" json:"versionId"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// Associations
+	Version JourneyVersion `gorm:"foreignKey:VersionID" json:"-"`
+}
+
+func (p *LearnerJourneyPin) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (LearnerJourneyPin) TableName() string {
+	return "learner_journey_pins"
+}
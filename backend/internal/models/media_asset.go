@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MediaAsset is an uploaded image or audio file. Identical uploads share one
+// asset, found by the hash of their content; each repeat upload marks it used
+// again, so garbage collection gives it a fresh grace period.
+type MediaAsset struct {
+	ID         string         `gorm:"primaryKey" json:"id"`
+	Hash       string         `gorm:"not null;uniqueIndex" json:"hash"` // SHA-256 of the uploaded file, hex encoded
+	Kind       string         `gorm:"not null;index" json:"kind"`       // 'image' | 'audio'
+	MIMEType   string         `gorm:"not null" json:"mimeType"`
+	URL        string         `gorm:"not null;uniqueIndex" json:"url"` // For images, the full variant in the original format
+	Size       int64          `gorm:"not null" json:"size"`            // Bytes stored, across all variants
+	Width      int            `json:"width,omitempty"`
+	Height     int            `json:"height,omitempty"`
+	Duration   *float64       `json:"duration,omitempty"` // Seconds, audio only
+	Variants   []MediaVariant `gorm:"serializer:json" json:"variants,omitempty"`
+	UploadedBy string         `gorm:"not null;index" json:"uploadedBy"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	LastUsedAt time.Time      `json:"lastUsedAt"` // Last upload of the same content
+}
+
+// MediaVariant is one resized copy of an image asset
+type MediaVariant struct {
+	Name     string `json:"name"` // 'thumbnail' | 'card' | 'full'
+	MIMEType string `json:"mimeType"`
+	URL      string `json:"url"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Size     int64  `json:"size"`
+}
+
+func (a *MediaAsset) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.New().String()
+	}
+	return nil
+}
+
+func (MediaAsset) TableName() string {
+	return "media_assets"
+}
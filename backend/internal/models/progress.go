@@ -9,8 +9,8 @@ import (
 
 type LearnerProgress struct {
 	ID           string         `gorm:"primaryKey" json:"id"`
-	UserID       string         `gorm:"not null;index" json:"userId"`
-	WordID       string         `gorm:"not null;index" json:"wordId"`
+	UserID       string         `gorm:"not null;uniqueIndex:idx_user_word" json:"userId"`
+	WordID       string         `gorm:"not null;uniqueIndex:idx_user_word;index" json:"wordId"`
 	MasteryLevel string         `gorm:"default:new" json:"masteryLevel"` // 'new' | 'learning' | 'review' | 'mastered'
 	ViewCount    int            `gorm:"default:0" json:"viewCount"`
 	LastViewedAt *time.Time     `json:"lastViewedAt"`
@@ -18,6 +18,14 @@ type LearnerProgress struct {
 	UpdatedAt    time.Time      `json:"updatedAt"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// Spaced-repetition schedule (SM-2)
+	EaseFactor     float64    `gorm:"not null;default:2.5" json:"easeFactor"`
+	IntervalDays   int        `gorm:"not null;default:0" json:"intervalDays"`
+	Repetitions    int        `gorm:"not null;default:0" json:"repetitions"` // Consecutive successful reviews
+	LapseCount     int        `gorm:"not null;default:0" json:"lapseCount"`
+	DueAt          *time.Time `gorm:"index" json:"dueAt"`
+	LastReviewedAt *time.Time `json:"lastReviewedAt"`
+
 	// Associations
 	User User `gorm:"foreignKey:UserID" json:"-"`
 	Word Word `gorm:"foreignKey:WordID" json:"-"`
@@ -30,15 +38,12 @@ func (lp *LearnerProgress) BeforeCreate(tx *gorm.DB) error {
 	if lp.MasteryLevel == "" {
 		lp.MasteryLevel = "new"
 	}
+	if lp.EaseFactor == 0 {
+		lp.EaseFactor = 2.5
+	}
 	return nil
 }
 
 func (LearnerProgress) TableName() string {
 	return "learner_progress"
 }
-
-// Ensure unique constraint on (user_id, word_id)
-type LearnerProgressIndex struct {
-	UserID string `gorm:"uniqueIndex:idx_user_word"`
-	WordID string `gorm:"uniqueIndex:idx_user_word"`
-}
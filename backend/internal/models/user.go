@@ -8,14 +8,15 @@ import (
 )
 
 type User struct {
-	ID           string         `gorm:"primaryKey" json:"id"`
-	Email        string         `gorm:"unique;not null" json:"email"`
-	PasswordHash string         `gorm:"not null" json:"-"`
-	Role         string         `gorm:"not null" json:"role"` // 'admin' | 'learner'
-	DisplayName  string         `json:"displayName"`
-	CreatedAt    time.Time      `json:"createdAt"`
-	UpdatedAt    time.Time      `json:"updatedAt"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              string         `gorm:"primaryKey" json:"id"`
+	Email           string         `gorm:"unique;not null" json:"email"`
+	PasswordHash    string         `gorm:"not null" json:"-"`
+	Role            string         `gorm:"not null" json:"role"` // 'admin' | 'learner'
+	DisplayName     string         `json:"displayName"`
+	EmailVerifiedAt *time.Time     `json:"emailVerifiedAt"` // Set once the user confirms their address
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (u *User) BeforeCreate(tx *gorm.DB) error {
@@ -15,6 +15,8 @@ type Word struct {
 	DisplayOrder     int            `gorm:"not null" json:"displayOrder"`
 	ImageURL         *string        `json:"imageUrl"`
 	AudioURL         *string        `json:"audioUrl"`
+	ImageAssetID     *string        `gorm:"index" json:"imageAssetId"`
+	AudioAssetID     *string        `gorm:"index" json:"audioAssetId"`
 	GenerationMethod string         `gorm:"default:manual" json:"generationMethod"` // 'manual' | 'ai_image' | 'ai_audio' | 'ai_both'
 	CreatedAt        time.Time      `json:"createdAt"`
 	UpdatedAt        time.Time      `json:"updatedAt"`
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/learng/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// Soft deletes cascade down Journey -> Scenario -> Word (plus quizzes and quiz
+// questions). Learner progress is left alone: published versions keep serving
+// deleted words, so progress only goes when the whole journey is purged.
+// Every row removed by one cascade gets the same deleted_at, which is how a
+// restore finds exactly that subtree again without reviving children that
+// were deleted on their own earlier.
+
+// deletionTime returns the timestamp stamped on a cascade. It is truncated to
+// microseconds so it compares equal after a round trip through any database.
+func deletionTime() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func cascadeDeleteJourney(tx *gorm.DB, id string, at time.Time) error {
+	if err := tx.Model(&models.Journey{}).Where("id = ?", id).Update("deleted_at", at).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Scenario{}).Where("journey_id = ?", id).Update("deleted_at", at).Error; err != nil {
+		return err
+	}
+	return cascadeDeleteScenarioChildren(tx, scenariosDeletedAt(tx, "journey_id = ?", id, at), at)
+}
+
+func cascadeDeleteScenario(tx *gorm.DB, id string, at time.Time) error {
+	if err := tx.Model(&models.Scenario{}).Where("id = ?", id).Update("deleted_at", at).Error; err != nil {
+		return err
+	}
+	return cascadeDeleteScenarioChildren(tx, scenariosDeletedAt(tx, "id = ?", id, at), at)
+}
+
+// cascadeDeleteScenarioChildren deletes the words and quizzes of the scenarios
+// selected by scenarioIDs
+func cascadeDeleteScenarioChildren(tx *gorm.DB, scenarioIDs *gorm.DB, at time.Time) error {
+	if err := tx.Model(&models.Word{}).Where("scenario_id IN (?)", scenarioIDs).Update("deleted_at", at).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Quiz{}).Where("scenario_id IN (?)", scenarioIDs).Update("deleted_at", at).Error; err != nil {
+		return err
+	}
+	quizIDs := tx.Unscoped().Model(&models.Quiz{}).Select("id").
+		Where("scenario_id IN (?) AND deleted_at = ?", scenarioIDs, at)
+	if err := tx.Model(&models.QuizQuestion{}).Where("quiz_id IN (?)", quizIDs).Update("deleted_at", at).Error; err != nil {
+		return err
+	}
+	wordIDs := tx.Unscoped().Model(&models.Word{}).Select("id").
+		Where("scenario_id IN (?) AND deleted_at = ?", scenarioIDs, at)
+	return cascadeDeleteWordChildren(tx, wordIDs, at)
+}
+
+func cascadeDeleteWord(tx *gorm.DB, id string, at time.Time) error {
+	if err := tx.Model(&models.Word{}).Where("id = ?", id).Update("deleted_at", at).Error; err != nil {
+		return err
+	}
+	return cascadeDeleteWordChildren(tx, []string{id}, at)
+}
+
+// cascadeDeleteWordChildren deletes the quiz questions of the words selected
+// by wordIDs, a subquery or an ID slice
+func cascadeDeleteWordChildren(tx *gorm.DB, wordIDs interface{}, at time.Time) error {
+	return tx.Model(&models.QuizQuestion{}).Where("word_id IN (?)", wordIDs).Update("deleted_at", at).Error
+}
+
+// scenariosDeletedAt selects the IDs of scenarios matching cond that were
+// deleted at the given time
+func scenariosDeletedAt(tx *gorm.DB, cond string, arg interface{}, at time.Time) *gorm.DB {
+	return tx.Unscoped().Model(&models.Scenario{}).Select("id").Where(cond, arg).Where("deleted_at = ?", at)
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/learng/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// ErrInviteUnavailable is returned when an invite has no use left, or has
+// expired or been revoked
+var ErrInviteUnavailable = errors.New("invite is exhausted, expired or revoked")
+
+type InviteRepository interface {
+	Create(invite *models.Invite) error
+	GetByID(id string) (*models.Invite, error)
+	GetByCodeHash(hash string) (*models.Invite, error)
+	GetAll() ([]models.Invite, error)
+	Revoke(id string) error
+}
+
+type inviteRepository struct {
+	db *gorm.DB
+}
+
+func NewInviteRepository(db *gorm.DB) InviteRepository {
+	return &inviteRepository{db: db}
+}
+
+func (r *inviteRepository) Create(invite *models.Invite) error {
+	return r.db.Create(invite).Error
+}
+
+func (r *inviteRepository) GetByID(id string) (*models.Invite, error) {
+	var invite models.Invite
+	if err := r.db.First(&invite, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *inviteRepository) GetByCodeHash(hash string) (*models.Invite, error) {
+	var invite models.Invite
+	if err := r.db.First(&invite, "code_hash = ?", hash).Error; err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+func (r *inviteRepository) GetAll() ([]models.Invite, error) {
+	var invites []models.Invite
+	if err := r.db.Order("created_at DESC").Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+func (r *inviteRepository) Revoke(id string) error {
+	return r.db.Model(&models.Invite{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// consumeInvite uses up one redemption of the invite within tx
+func consumeInvite(tx *gorm.DB, id string) error {
+	result := tx.Model(&models.Invite{}).
+		Where("id = ? AND use_count < max_uses AND revoked_at IS NULL AND expires_at > ?", id, time.Now()).
+		Update("use_count", gorm.Expr("use_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInviteUnavailable
+	}
+	return nil
+}
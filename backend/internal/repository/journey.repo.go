@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/learng/backend/internal/models"
 	"gorm.io/gorm"
 )
@@ -13,6 +15,10 @@ type JourneyRepository interface {
 	Delete(id string) error
 	GetByIDWithScenarios(id string) (*models.Journey, error)
 	GetByCreator(creatorID string, status string) ([]models.Journey, error)
+	CreateTree(journey *models.Journey) error
+	UpdateStatus(id, fromStatus string, updates map[string]interface{}) (bool, error)
+	GetScheduledDue(now time.Time) ([]models.Journey, error)
+	GetPublishedUnversioned() ([]models.Journey, error)
 }
 
 type journeyRepository struct {
@@ -67,8 +73,11 @@ func (r *journeyRepository) Update(journey *models.Journey) error {
 	return r.db.Save(journey).Error
 }
 
+// Delete soft deletes the journey and, in the same transaction, its scenarios, words and quizzes
 func (r *journeyRepository) Delete(id string) error {
-	return r.db.Delete(&models.Journey{}, "id = ?", id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return cascadeDeleteJourney(tx, id, deletionTime())
+	})
 }
 
 func (r *journeyRepository) GetByIDWithScenarios(id string) (*models.Journey, error) {
@@ -94,3 +103,45 @@ func (r *journeyRepository) GetByCreator(creatorID string, status string) ([]mod
 	}
 	return journeys, nil
 }
+
+// CreateTree inserts the journey with its scenarios, words, quizzes and quiz
+// questions in a single transaction
+func (r *journeyRepository) CreateTree(journey *models.Journey) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.Omit("Creator").Create(journey).Error
+	})
+}
+
+// UpdateStatus applies updates only if the journey is still in fromStatus,
+// reporting whether it was. This keeps concurrent status changes, such as the
+// scheduled publisher and an admin unpublishing, from overwriting each other.
+func (r *journeyRepository) UpdateStatus(id, fromStatus string, updates map[string]interface{}) (bool, error) {
+	result := r.db.Model(&models.Journey{}).
+		Where("id = ? AND status = ?", id, fromStatus).
+		Updates(updates)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// GetScheduledDue returns scheduled journeys whose release time has passed
+func (r *journeyRepository) GetScheduledDue(now time.Time) ([]models.Journey, error) {
+	var journeys []models.Journey
+	if err := r.db.Where("status = ? AND publish_at <= ?", "scheduled", now).
+		Order("publish_at ASC").Find(&journeys).Error; err != nil {
+		return nil, err
+	}
+	return journeys, nil
+}
+
+// GetPublishedUnversioned returns published journeys that have no live
+// version yet, i.e. ones published before versioning existed
+func (r *journeyRepository) GetPublishedUnversioned() ([]models.Journey, error) {
+	var journeys []models.Journey
+	if err := r.db.Where("status = ? AND live_version_id IS NULL", "published").
+		Find(&journeys).Error; err != nil {
+		return nil, err
+	}
+	return journeys, nil
+}
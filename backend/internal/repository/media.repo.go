@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/learng/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// MediaAssetUsage is an asset with the number of live words that use it
+type MediaAssetUsage struct {
+	models.MediaAsset
+	UsageCount int64
+}
+
+// UnlinkedMedia is an uploaded media URL that words use without an asset, and the
+// owner of one of the journeys those words belong to
+type UnlinkedMedia struct {
+	URL     string
+	OwnerID string
+}
+
+type MediaAssetRepository interface {
+	Create(asset *models.MediaAsset) error
+	GetByID(id string) (*models.MediaAsset, error)
+	GetByHash(hash string) (*models.MediaAsset, error)
+	GetByURL(url string) (*models.MediaAsset, error)
+	MarkUsed(id string) error
+	Delete(id string) error
+	ListWithUsage(kind string) ([]MediaAssetUsage, error)
+	GetAll() ([]models.MediaAsset, error)
+	GetWordURLs() ([]string, error)
+	GetUnlinked(urlPrefix string) ([]UnlinkedMedia, error)
+	LinkWords(asset *models.MediaAsset) error
+}
+
+type mediaAssetRepository struct {
+	db *gorm.DB
+}
+
+func NewMediaAssetRepository(db *gorm.DB) MediaAssetRepository {
+	return &mediaAssetRepository{db: db}
+}
+
+func (r *mediaAssetRepository) Create(asset *models.MediaAsset) error {
+	return r.db.Create(asset).Error
+}
+
+func (r *mediaAssetRepository) GetByID(id string) (*models.MediaAsset, error) {
+	var asset models.MediaAsset
+	if err := r.db.First(&asset, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (r *mediaAssetRepository) GetByHash(hash string) (*models.MediaAsset, error) {
+	var asset models.MediaAsset
+	if err := r.db.First(&asset, "hash = ?", hash).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (r *mediaAssetRepository) GetByURL(url string) (*models.MediaAsset, error) {
+	var asset models.MediaAsset
+	if err := r.db.First(&asset, "url = ?", url).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+// MarkUsed sets the asset's last used time to now. It returns
+// gorm.ErrRecordNotFound when the asset is gone, e.g. collected meanwhile.
+func (r *mediaAssetRepository) MarkUsed(id string) error {
+	result := r.db.Model(&models.MediaAsset{}).Where("id = ?", id).
+		UpdateColumn("last_used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *mediaAssetRepository) Delete(id string) error {
+	return r.db.Delete(&models.MediaAsset{}, "id = ?", id).Error
+}
+
+// ListWithUsage returns assets of the given kind, or of every kind when kind
+// is empty, newest first. Soft-deleted words do not count as usage.
+func (r *mediaAssetRepository) ListWithUsage(kind string) ([]MediaAssetUsage, error) {
+	usage := r.db.Model(&models.Word{}).Select("COUNT(*)").
+		Where("words.image_asset_id = media_assets.id OR words.audio_asset_id = media_assets.id")
+
+	query := r.db.Model(&models.MediaAsset{}).
+		Select("media_assets.*, (?) AS usage_count", usage).
+		Order("media_assets.created_at DESC")
+	if kind != "" {
+		query = query.Where("media_assets.kind = ?", kind)
+	}
+
+	var assets []MediaAssetUsage
+	if err := query.Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+func (r *mediaAssetRepository) GetAll() ([]models.MediaAsset, error) {
+	var assets []models.MediaAsset
+	if err := r.db.Order("created_at ASC").Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// GetWordURLs returns every image and audio URL that words, deleted or not,
+// point at
+func (r *mediaAssetRepository) GetWordURLs() ([]string, error) {
+	var urls []string
+	for _, column := range []string{"image_url", "audio_url"} {
+		var values []string
+		if err := r.db.Unscoped().Model(&models.Word{}).
+			Distinct(column).
+			Where(column+" IS NOT NULL AND "+column+" <> ''").
+			Pluck(column, &values).Error; err != nil {
+			return nil, err
+		}
+		urls = append(urls, values...)
+	}
+	return urls, nil
+}
+
+// GetUnlinked returns the /uploads/ URLs that words, deleted or not, use
+// without a linked asset
+func (r *mediaAssetRepository) GetUnlinked(urlPrefix string) ([]UnlinkedMedia, error) {
+	var unlinked []UnlinkedMedia
+	for _, column := range []string{"image", "audio"} {
+		var rows []UnlinkedMedia
+		err := r.db.Unscoped().Model(&models.Word{}).
+			Select("words."+column+"_url AS url, MIN(journeys.created_by) AS owner_id").
+			Joins("JOIN scenarios ON scenarios.id = words.scenario_id").
+			Joins("JOIN journeys ON journeys.id = scenarios.journey_id").
+			Where("words."+column+"_url LIKE ? AND words."+column+"_asset_id IS NULL", urlPrefix+"%").
+			Group("words." + column + "_url").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		unlinked = append(unlinked, rows...)
+	}
+	return unlinked, nil
+}
+
+// LinkWords links every word, deleted or not, that uses the asset's URL
+// without an asset to it
+func (r *mediaAssetRepository) LinkWords(asset *models.MediaAsset) error {
+	column := "image"
+	if asset.Kind == "audio" {
+		column = "audio"
+	}
+	return r.db.Unscoped().Model(&models.Word{}).
+		Where(column+"_url = ? AND "+column+"_asset_id IS NULL", asset.URL).
+		UpdateColumn(column+"_asset_id", asset.ID).Error
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrOrderMismatch is returned by a reorder whose ID list does not name every
+// current child exactly once
+var ErrOrderMismatch = errors.New("order does not match current items")
+
+// nextDisplayOrder returns the DisplayOrder following the last live row of
+// model whose parentColumn equals parentID
+func nextDisplayOrder(tx *gorm.DB, model interface{}, parentColumn, parentID string) (int, error) {
+	var maxOrder int
+	if err := tx.Model(model).Where(parentColumn+" = ?", parentID).
+		Select("COALESCE(MAX(display_order), 0)").Scan(&maxOrder).Error; err != nil {
+		return 0, err
+	}
+	return maxOrder + 1, nil
+}
+
+// renumber sets DisplayOrder to 1..n following ids, which must list the live
+// children of parentID exactly once each. It runs in its own transaction so
+// the check and the update see the same set of rows.
+func renumber(db *gorm.DB, model interface{}, parentColumn, parentID string, ids []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var current []string
+		if err := tx.Model(model).Where(parentColumn+" = ?", parentID).Pluck("id", &current).Error; err != nil {
+			return err
+		}
+		if len(current) != len(ids) {
+			return ErrOrderMismatch
+		}
+		remaining := make(map[string]bool, len(current))
+		for _, id := range current {
+			remaining[id] = true
+		}
+		for _, id := range ids {
+			if !remaining[id] {
+				return ErrOrderMismatch
+			}
+			delete(remaining, id)
+		}
+
+		for i, id := range ids {
+			if err := tx.Model(model).Where("id = ?", id).Update("display_order", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/learng/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProgressRepository interface {
+	GetByUserAndWord(userID, wordID string) (*models.LearnerProgress, error)
+	Update(userID, wordID string, update func(progress *models.LearnerProgress)) (*models.LearnerProgress, error)
+	GetByUserAndWordIDs(userID string, wordIDs []string) ([]models.LearnerProgress, error)
+	GetDue(userID string, now time.Time, offset, limit int) ([]models.LearnerProgress, error)
+	CountReviewedSince(userID string, since time.Time) (int64, error)
+}
+
+type progressRepository struct {
+	db *gorm.DB
+}
+
+func NewProgressRepository(db *gorm.DB) ProgressRepository {
+	return &progressRepository{db: db}
+}
+
+func (r *progressRepository) GetByUserAndWord(userID, wordID string) (*models.LearnerProgress, error) {
+	var progress models.LearnerProgress
+	if err := r.db.First(&progress, "user_id = ? AND word_id = ?", userID, wordID).Error; err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// Update applies update to the user's progress on the word, or to a new
+// 'new' row when there is none, and saves it. The read and the save run in
+// one transaction that starts by writing, so SQLite makes a concurrent update
+// of the same progress wait for this one rather than read the row before it
+// is saved and overwrite it.
+func (r *progressRepository) Update(userID, wordID string, update func(progress *models.LearnerProgress)) (*models.LearnerProgress, error) {
+	var progress models.LearnerProgress
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.LearnerProgress{}).
+			Where("user_id = ? AND word_id = ?", userID, wordID).
+			UpdateColumn("updated_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		err := tx.First(&progress, "user_id = ? AND word_id = ?", userID, wordID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			progress = models.LearnerProgress{UserID: userID, WordID: wordID, MasteryLevel: "new"}
+		} else if err != nil {
+			return err
+		}
+
+		update(&progress)
+		if err := upsertProgress(tx, &progress); err != nil {
+			return err
+		}
+		// Re-read so a row revived from soft deletion carries its persisted ID
+		return tx.First(&progress, "user_id = ? AND word_id = ?", userID, wordID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// upsertProgress inserts the progress row or overwrites the existing one on
+// the (user_id, word_id) unique key, reviving it if it was soft-deleted
+func upsertProgress(tx *gorm.DB, progress *models.LearnerProgress) error {
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "word_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"mastery_level":    progress.MasteryLevel,
+			"view_count":       progress.ViewCount,
+			"last_viewed_at":   progress.LastViewedAt,
+			"ease_factor":      progress.EaseFactor,
+			"interval_days":    progress.IntervalDays,
+			"repetitions":      progress.Repetitions,
+			"lapse_count":      progress.LapseCount,
+			"due_at":           progress.DueAt,
+			"last_reviewed_at": progress.LastReviewedAt,
+			"updated_at":       time.Now(),
+			"deleted_at":       nil,
+		}),
+	}).Create(progress).Error
+}
+
+func (r *progressRepository) GetByUserAndWordIDs(userID string, wordIDs []string) ([]models.LearnerProgress, error) {
+	var progress []models.LearnerProgress
+	if len(wordIDs) == 0 {
+		return progress, nil
+	}
+	if err := r.db.Where("user_id = ? AND word_id IN ?", userID, wordIDs).Find(&progress).Error; err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// GetDue returns a page of the user's progress rows due for review at now,
+// limited to words in published journeys and ordered most overdue first, then
+// hardest first. Words and scenarios deleted from the draft still count, since
+// the learner's published version may include them.
+func (r *progressRepository) GetDue(userID string, now time.Time, offset, limit int) ([]models.LearnerProgress, error) {
+	var progress []models.LearnerProgress
+	if err := r.db.
+		Joins("JOIN words ON words.id = learner_progress.word_id").
+		Joins("JOIN scenarios ON scenarios.id = words.scenario_id").
+		Joins("JOIN journeys ON journeys.id = scenarios.journey_id AND journeys.deleted_at IS NULL").
+		Where("journeys.status = ?", "published").
+		Where("learner_progress.user_id = ? AND learner_progress.due_at <= ?", userID, now).
+		Order("learner_progress.due_at ASC, learner_progress.ease_factor ASC, learner_progress.id ASC").
+		Offset(offset).
+		Limit(limit).
+		Preload("Word", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("Word.Scenario", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Find(&progress).Error; err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+func (r *progressRepository) CountReviewedSince(userID string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.LearnerProgress{}).
+		Where("user_id = ? AND last_reviewed_at >= ?", userID, since).
+		Count(&count).Error
+	return count, err
+}
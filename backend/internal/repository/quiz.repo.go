@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"github.com/learng/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type QuizRepository interface {
+	Create(quiz *models.Quiz) error
+	GetByID(id string) (*models.Quiz, error)
+	GetByIDWithQuestions(id string) (*models.Quiz, error)
+	GetByScenarioID(scenarioID string) ([]models.Quiz, error)
+	GetByScenarioIDsWithQuestions(scenarioIDs []string) ([]models.Quiz, error)
+	GetJourneyID(id string) (string, error)
+	CreateAttempt(attempt *models.QuizAttempt) error
+}
+
+type quizRepository struct {
+	db *gorm.DB
+}
+
+func NewQuizRepository(db *gorm.DB) QuizRepository {
+	return &quizRepository{db: db}
+}
+
+// Create inserts the quiz together with its questions
+func (r *quizRepository) Create(quiz *models.Quiz) error {
+	return r.db.Create(quiz).Error
+}
+
+func (r *quizRepository) GetByID(id string) (*models.Quiz, error) {
+	var quiz models.Quiz
+	if err := r.db.First(&quiz, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &quiz, nil
+}
+
+func (r *quizRepository) GetByIDWithQuestions(id string) (*models.Quiz, error) {
+	var quiz models.Quiz
+	if err := r.db.Preload("Questions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("display_order ASC")
+	}).First(&quiz, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &quiz, nil
+}
+
+func (r *quizRepository) GetByScenarioID(scenarioID string) ([]models.Quiz, error) {
+	var quizzes []models.Quiz
+	if err := r.db.Where("scenario_id = ?", scenarioID).Order("created_at DESC").Find(&quizzes).Error; err != nil {
+		return nil, err
+	}
+	return quizzes, nil
+}
+
+func (r *quizRepository) GetByScenarioIDsWithQuestions(scenarioIDs []string) ([]models.Quiz, error) {
+	var quizzes []models.Quiz
+	if len(scenarioIDs) == 0 {
+		return quizzes, nil
+	}
+	if err := r.db.Preload("Questions", func(db *gorm.DB) *gorm.DB {
+		return db.Order("display_order ASC")
+	}).Where("scenario_id IN ?", scenarioIDs).Order("created_at ASC").Find(&quizzes).Error; err != nil {
+		return nil, err
+	}
+	return quizzes, nil
+}
+
+func (r *quizRepository) CreateAttempt(attempt *models.QuizAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+// GetJourneyID returns the journey a quiz belongs to, even when the quiz has
+// since been deleted from the draft
+func (r *quizRepository) GetJourneyID(id string) (string, error) {
+	var journeyID string
+	result := r.db.Unscoped().Model(&models.Quiz{}).
+		Joins("JOIN scenarios ON scenarios.id = quizzes.scenario_id").
+		Where("quizzes.id = ?", id).
+		Limit(1).
+		Pluck("scenarios.journey_id", &journeyID)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return journeyID, nil
+}
@@ -12,6 +12,8 @@ type ScenarioRepository interface {
 	Update(scenario *models.Scenario) error
 	Delete(id string) error
 	GetByIDWithWords(id string) (*models.Scenario, error)
+	Reorder(journeyID string, ids []string) error
+	GetJourneyID(id string) (string, error)
 }
 
 type scenarioRepository struct {
@@ -22,8 +24,20 @@ func NewScenarioRepository(db *gorm.DB) ScenarioRepository {
 	return &scenarioRepository{db: db}
 }
 
+// Create inserts the scenario, placing it after the journey's last scenario
+// when no DisplayOrder is given
 func (r *scenarioRepository) Create(scenario *models.Scenario) error {
-	return r.db.Create(scenario).Error
+	if scenario.DisplayOrder != 0 {
+		return r.db.Create(scenario).Error
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		next, err := nextDisplayOrder(tx, &models.Scenario{}, "journey_id", scenario.JourneyID)
+		if err != nil {
+			return err
+		}
+		scenario.DisplayOrder = next
+		return tx.Create(scenario).Error
+	})
 }
 
 func (r *scenarioRepository) GetByID(id string) (*models.Scenario, error) {
@@ -46,8 +60,11 @@ func (r *scenarioRepository) Update(scenario *models.Scenario) error {
 	return r.db.Save(scenario).Error
 }
 
+// Delete soft deletes the scenario and, in the same transaction, its words and quizzes
 func (r *scenarioRepository) Delete(id string) error {
-	return r.db.Delete(&models.Scenario{}, "id = ?", id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return cascadeDeleteScenario(tx, id, deletionTime())
+	})
 }
 
 func (r *scenarioRepository) GetByIDWithWords(id string) (*models.Scenario, error) {
@@ -59,3 +76,19 @@ func (r *scenarioRepository) GetByIDWithWords(id string) (*models.Scenario, erro
 	}
 	return &scenario, nil
 }
+
+// Reorder renumbers the journey's scenarios to follow ids, which must list
+// each of them exactly once; otherwise ErrOrderMismatch is returned
+func (r *scenarioRepository) Reorder(journeyID string, ids []string) error {
+	return renumber(r.db, &models.Scenario{}, "journey_id", journeyID, ids)
+}
+
+// GetJourneyID returns the journey a scenario belongs to, even when the
+// scenario has since been deleted from the draft
+func (r *scenarioRepository) GetJourneyID(id string) (string, error) {
+	var scenario models.Scenario
+	if err := r.db.Unscoped().Select("journey_id").First(&scenario, "id = ?", id).Error; err != nil {
+		return "", err
+	}
+	return scenario.JourneyID, nil
+}
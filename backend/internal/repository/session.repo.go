@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/learng/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	Create(session *models.Session) error
+	GetByID(id string) (*models.Session, error)
+	Rotate(id, oldHash, newHash string, expiresAt time.Time) (bool, error)
+	Revoke(id string) error
+	RevokeAllForUser(userID string) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+func (r *sessionRepository) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *sessionRepository) GetByID(id string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.First(&session, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// Rotate swaps the session's token hash only if oldHash is still current and
+// the session is not revoked; it reports false when another rotation won
+func (r *sessionRepository) Rotate(id, oldHash, newHash string, expiresAt time.Time) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND token_hash = ? AND revoked_at IS NULL", id, oldHash).
+		Updates(map[string]interface{}{
+			"token_hash":   newHash,
+			"expires_at":   expiresAt,
+			"last_used_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *sessionRepository) Revoke(id string) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *sessionRepository) RevokeAllForUser(userID string) error {
+	return r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,362 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/learng/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+// TrashEntry is a soft-deleted journey, scenario or word whose parent is
+// still live, i.e. something a user deleted directly rather than by cascade
+type TrashEntry struct {
+	Type      string // 'journey' | 'scenario' | 'word'
+	ID        string
+	Title     string
+	ParentID  string
+	DeletedAt time.Time
+}
+
+// PurgeCounts is the number of rows hard-deleted per table
+type PurgeCounts map[string]int64
+
+type TrashRepository interface {
+	GetDeletedJourney(id string) (*models.Journey, error)
+	GetDeletedScenario(id string) (*models.Scenario, error)
+	GetDeletedWord(id string) (*models.Word, error)
+	RestoreJourney(journey *models.Journey) error
+	RestoreScenario(scenario *models.Scenario) error
+	RestoreWord(word *models.Word) error
+	ListByOwner(ownerID string) ([]TrashEntry, error)
+	Purge(before time.Time) (PurgeCounts, error)
+}
+
+type trashRepository struct {
+	db *gorm.DB
+}
+
+func NewTrashRepository(db *gorm.DB) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+func (r *trashRepository) GetDeletedJourney(id string) (*models.Journey, error) {
+	var journey models.Journey
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&journey, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &journey, nil
+}
+
+// GetDeletedScenario also loads the scenario's journey, deleted or not
+func (r *trashRepository) GetDeletedScenario(id string) (*models.Scenario, error) {
+	var scenario models.Scenario
+	if err := r.db.Unscoped().Preload("Journey", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Where("deleted_at IS NOT NULL").First(&scenario, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+// GetDeletedWord also loads the word's scenario and journey, deleted or not
+func (r *trashRepository) GetDeletedWord(id string) (*models.Word, error) {
+	var word models.Word
+	if err := r.db.Unscoped().Preload("Scenario", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Scenario.Journey", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Where("deleted_at IS NOT NULL").First(&word, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &word, nil
+}
+
+// RestoreJourney undeletes the journey and every row its delete cascaded to
+func (r *trashRepository) RestoreJourney(journey *models.Journey) error {
+	at := journey.DeletedAt.Time
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreWhere(tx, &models.Journey{}, at, "id = ?", journey.ID); err != nil {
+			return err
+		}
+		scenarioIDs := tx.Unscoped().Model(&models.Scenario{}).Select("id").Where("journey_id = ?", journey.ID)
+		if err := restoreWhere(tx, &models.Scenario{}, at, "journey_id = ?", journey.ID); err != nil {
+			return err
+		}
+		return restoreScenarioChildren(tx, scenarioIDs, at)
+	})
+}
+
+// RestoreScenario undeletes the scenario and every row its delete cascaded to
+func (r *trashRepository) RestoreScenario(scenario *models.Scenario) error {
+	at := scenario.DeletedAt.Time
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreWhere(tx, &models.Scenario{}, at, "id = ?", scenario.ID); err != nil {
+			return err
+		}
+		return restoreScenarioChildren(tx, []string{scenario.ID}, at)
+	})
+}
+
+// RestoreWord undeletes the word and every row its delete cascaded to
+func (r *trashRepository) RestoreWord(word *models.Word) error {
+	at := word.DeletedAt.Time
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := restoreWhere(tx, &models.Word{}, at, "id = ?", word.ID); err != nil {
+			return err
+		}
+		return restoreWordChildren(tx, []string{word.ID}, at)
+	})
+}
+
+func restoreScenarioChildren(tx *gorm.DB, scenarioIDs interface{}, at time.Time) error {
+	if err := restoreWhere(tx, &models.Word{}, at, "scenario_id IN (?)", scenarioIDs); err != nil {
+		return err
+	}
+	if err := restoreWhere(tx, &models.Quiz{}, at, "scenario_id IN (?)", scenarioIDs); err != nil {
+		return err
+	}
+	quizIDs := tx.Unscoped().Model(&models.Quiz{}).Select("id").Where("scenario_id IN (?)", scenarioIDs)
+	if err := restoreWhere(tx, &models.QuizQuestion{}, at, "quiz_id IN (?)", quizIDs); err != nil {
+		return err
+	}
+	wordIDs := tx.Unscoped().Model(&models.Word{}).Select("id").Where("scenario_id IN (?)", scenarioIDs)
+	return restoreWordChildren(tx, wordIDs, at)
+}
+
+func restoreWordChildren(tx *gorm.DB, wordIDs interface{}, at time.Time) error {
+	return restoreWhere(tx, &models.QuizQuestion{}, at, "word_id IN (?)", wordIDs)
+}
+
+// restoreWhere clears deleted_at on rows matching cond that were deleted at at
+func restoreWhere(tx *gorm.DB, model interface{}, at time.Time, cond string, arg interface{}) error {
+	return tx.Unscoped().Model(model).Where(cond, arg).Where("deleted_at = ?", at).Update("deleted_at", nil).Error
+}
+
+// ListByOwner returns the directly deleted journeys, scenarios and words of
+// the owner's journeys, most recently deleted first
+func (r *trashRepository) ListByOwner(ownerID string) ([]TrashEntry, error) {
+	var entries []TrashEntry
+
+	var journeys []models.Journey
+	if err := r.db.Unscoped().
+		Where("created_by = ? AND deleted_at IS NOT NULL", ownerID).
+		Find(&journeys).Error; err != nil {
+		return nil, err
+	}
+	for _, journey := range journeys {
+		entries = append(entries, TrashEntry{
+			Type:      "journey",
+			ID:        journey.ID,
+			Title:     journey.Title,
+			DeletedAt: journey.DeletedAt.Time,
+		})
+	}
+
+	var scenarios []models.Scenario
+	if err := r.db.Unscoped().
+		Joins("JOIN journeys ON journeys.id = scenarios.journey_id AND journeys.deleted_at IS NULL").
+		Where("journeys.created_by = ? AND scenarios.deleted_at IS NOT NULL", ownerID).
+		Find(&scenarios).Error; err != nil {
+		return nil, err
+	}
+	for _, scenario := range scenarios {
+		entries = append(entries, TrashEntry{
+			Type:      "scenario",
+			ID:        scenario.ID,
+			Title:     scenario.Title,
+			ParentID:  scenario.JourneyID,
+			DeletedAt: scenario.DeletedAt.Time,
+		})
+	}
+
+	var words []models.Word
+	if err := r.db.Unscoped().
+		Joins("JOIN scenarios ON scenarios.id = words.scenario_id AND scenarios.deleted_at IS NULL").
+		Joins("JOIN journeys ON journeys.id = scenarios.journey_id AND journeys.deleted_at IS NULL").
+		Where("journeys.created_by = ? AND words.deleted_at IS NOT NULL", ownerID).
+		Find(&words).Error; err != nil {
+		return nil, err
+	}
+	for _, word := range words {
+		entries = append(entries, TrashEntry{
+			Type:      "word",
+			ID:        word.ID,
+			Title:     word.TargetText,
+			ParentID:  word.ScenarioID,
+			DeletedAt: word.DeletedAt.Time,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].DeletedAt.After(entries[j].DeletedAt)
+	})
+	return entries, nil
+}
+
+// Purge hard-deletes everything soft-deleted before the cutoff, together with
+// any rows that belong to a purged parent, in one transaction. Scenarios,
+// words and quizzes still listed in a published version of a live journey are
+// kept, since learners pinned to that version go on reading them.
+func (r *trashRepository) Purge(before time.Time) (PurgeCounts, error) {
+	counts := PurgeCounts{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var journeyIDs, scenarioIDs, wordIDs, quizIDs, questionIDs []string
+
+		expired := "deleted_at IS NOT NULL AND deleted_at < ?"
+		if err := tx.Unscoped().Model(&models.Journey{}).Where(expired, before).Pluck("id", &journeyIDs).Error; err != nil {
+			return err
+		}
+		published, err := publishedIDs(tx, journeyIDs)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Model(&models.Scenario{}).
+			Where(expired, before).Or("journey_id IN ?", nonEmpty(journeyIDs)).
+			Pluck("id", &scenarioIDs).Error; err != nil {
+			return err
+		}
+		scenarioIDs = published.without(scenarioIDs)
+		if err := tx.Unscoped().Model(&models.Word{}).
+			Where(expired, before).Or("scenario_id IN ?", nonEmpty(scenarioIDs)).
+			Pluck("id", &wordIDs).Error; err != nil {
+			return err
+		}
+		wordIDs = published.without(wordIDs)
+		if err := tx.Unscoped().Model(&models.Quiz{}).
+			Where(expired, before).Or("scenario_id IN ?", nonEmpty(scenarioIDs)).
+			Pluck("id", &quizIDs).Error; err != nil {
+			return err
+		}
+		quizIDs = published.without(quizIDs)
+		if err := tx.Unscoped().Model(&models.QuizQuestion{}).
+			Where(expired, before).Or("quiz_id IN ?", nonEmpty(quizIDs)).Or("word_id IN ?", nonEmpty(wordIDs)).
+			Pluck("id", &questionIDs).Error; err != nil {
+			return err
+		}
+		questionIDs = published.without(questionIDs)
+
+		// Children go first so nothing is left pointing at a purged row
+		steps := []struct {
+			table string
+			run   func() *gorm.DB
+		}{
+			// Learner history goes with its word or quiz; rows a published
+			// version lists are not purged, so neither is their history
+			{"learner_progress", func() *gorm.DB {
+				return tx.Unscoped().Where("word_id IN ?", nonEmpty(wordIDs)).Delete(&models.LearnerProgress{})
+			}},
+			{"quiz_attempts", func() *gorm.DB {
+				return tx.Where("quiz_id IN ?", nonEmpty(quizIDs)).Delete(&models.QuizAttempt{})
+			}},
+			{"learner_journey_pins", func() *gorm.DB {
+				return tx.Where("journey_id IN ?", nonEmpty(journeyIDs)).Delete(&models.LearnerJourneyPin{})
+			}},
+			{"journey_versions", func() *gorm.DB {
+				return tx.Where("journey_id IN ?", nonEmpty(journeyIDs)).Delete(&models.JourneyVersion{})
+			}},
+			{"quiz_questions", func() *gorm.DB {
+				return tx.Unscoped().Where("id IN ?", nonEmpty(questionIDs)).Delete(&models.QuizQuestion{})
+			}},
+			{"quizzes", func() *gorm.DB {
+				return tx.Unscoped().Where("id IN ?", nonEmpty(quizIDs)).Delete(&models.Quiz{})
+			}},
+			{"words", func() *gorm.DB {
+				return tx.Unscoped().Where("id IN ?", nonEmpty(wordIDs)).Delete(&models.Word{})
+			}},
+			{"scenarios", func() *gorm.DB {
+				return tx.Unscoped().Where("id IN ?", nonEmpty(scenarioIDs)).Delete(&models.Scenario{})
+			}},
+			{"journeys", func() *gorm.DB {
+				return tx.Unscoped().Where("id IN ?", nonEmpty(journeyIDs)).Delete(&models.Journey{})
+			}},
+		}
+		for _, step := range steps {
+			result := step.run()
+			if result.Error != nil {
+				return result.Error
+			}
+			counts[step.table] = result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// idSet holds the IDs of rows that must not be purged
+type idSet map[string]bool
+
+// without returns ids less those in the set
+func (set idSet) without(ids []string) []string {
+	kept := ids[:0]
+	for _, id := range ids {
+		if !set[id] {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
+// versionRefs is the part of a version snapshot that names draft rows
+type versionRefs struct {
+	Scenarios []struct {
+		ID    string `json:"id"`
+		Words []struct {
+			ID string `json:"id"`
+		} `json:"words"`
+		Quizzes []struct {
+			ID        string `json:"id"`
+			Questions []struct {
+				ID     string `json:"id"`
+				WordID string `json:"wordId"`
+			} `json:"questions"`
+		} `json:"quizzes"`
+	} `json:"scenarios"`
+}
+
+// publishedIDs collects the scenarios, words, quizzes and questions listed in
+// the versions of every journey except those being purged
+func publishedIDs(tx *gorm.DB, purgedJourneyIDs []string) (idSet, error) {
+	var snapshots []string
+	if err := tx.Model(&models.JourneyVersion{}).
+		Where("journey_id NOT IN ?", nonEmpty(purgedJourneyIDs)).
+		Pluck("snapshot", &snapshots).Error; err != nil {
+		return nil, err
+	}
+
+	set := idSet{}
+	for _, snapshot := range snapshots {
+		var refs versionRefs
+		if err := json.Unmarshal([]byte(snapshot), &refs); err != nil {
+			return nil, fmt.Errorf("invalid version snapshot: %w", err)
+		}
+		for _, scenario := range refs.Scenarios {
+			set[scenario.ID] = true
+			for _, word := range scenario.Words {
+				set[word.ID] = true
+			}
+			for _, quiz := range scenario.Quizzes {
+				set[quiz.ID] = true
+				for _, question := range quiz.Questions {
+					set[question.ID] = true
+					set[question.WordID] = true
+				}
+			}
+		}
+	}
+	return set, nil
+}
+
+// nonEmpty keeps an IN clause valid when there are no IDs to match
+func nonEmpty(ids []string) []string {
+	if len(ids) == 0 {
+		return []string{""}
+	}
+	return ids
+}
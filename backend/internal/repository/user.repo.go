@@ -20,6 +20,17 @@ func (r *UserRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
 
+// CreateWithInvite creates a new user and redeems one use of the invite they
+// registered with, or neither
+func (r *UserRepository) CreateWithInvite(user *models.User, inviteID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := consumeInvite(tx, inviteID); err != nil {
+			return err
+		}
+		return tx.Create(user).Error
+	})
+}
+
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(id string) (*models.User, error) {
 	var user models.User
@@ -62,3 +73,10 @@ func (r *UserRepository) Exists(email string) (bool, error) {
 	err := r.db.Model(&models.User{}).Where("email = ?", email).Count(&count).Error
 	return count > 0, err
 }
+
+// Count returns the number of users
+func (r *UserRepository) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Count(&count).Error
+	return count, err
+}
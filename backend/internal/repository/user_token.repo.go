@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/learng/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+type UserTokenRepository interface {
+	Create(token *models.UserToken) error
+	GetByHash(purpose, hash string) (*models.UserToken, error)
+	MarkUsed(id string) (bool, error)
+	InvalidateForUser(userID, purpose string) error
+}
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(token *models.UserToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *userTokenRepository) GetByHash(purpose, hash string) (*models.UserToken, error) {
+	var token models.UserToken
+	if err := r.db.First(&token, "purpose = ? AND token_hash = ?", purpose, hash).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed consumes the token, reporting false if it was already used
+func (r *userTokenRepository) MarkUsed(id string) (bool, error) {
+	result := r.db.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// InvalidateForUser consumes all of the user's outstanding tokens for a purpose
+func (r *userTokenRepository) InvalidateForUser(userID, purpose string) error {
+	return r.db.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", time.Now()).Error
+}
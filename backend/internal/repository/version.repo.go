@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"github.com/learng/backend/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type VersionRepository interface {
+	Publish(journeyID, fromStatus string, updates map[string]interface{}, version *models.JourneyVersion) (bool, error)
+	GetByID(id string) (*models.JourneyVersion, error)
+	GetByJourneyID(journeyID string) ([]models.JourneyVersion, error)
+	GetAll() ([]models.JourneyVersion, error)
+	GetPin(userID, journeyID string) (*models.LearnerJourneyPin, error)
+	UpsertPin(pin *models.LearnerJourneyPin) error
+}
+
+type versionRepository struct {
+	db *gorm.DB
+}
+
+func NewVersionRepository(db *gorm.DB) VersionRepository {
+	return &versionRepository{db: db}
+}
+
+// Publish applies updates to the journey if it is still in fromStatus and, in
+// the same transaction, stores version as the journey's next version and makes
+// it live. It reports whether the journey was still in fromStatus.
+func (r *versionRepository) Publish(journeyID, fromStatus string, updates map[string]interface{}, version *models.JourneyVersion) (bool, error) {
+	published := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Journey{}).
+			Where("id = ? AND status = ?", journeyID, fromStatus).
+			Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return nil
+		}
+
+		var latest int
+		if err := tx.Model(&models.JourneyVersion{}).
+			Where("journey_id = ?", journeyID).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&latest).Error; err != nil {
+			return err
+		}
+		version.JourneyID = journeyID
+		version.Version = latest + 1
+		if err := tx.Create(version).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Journey{}).Where("id = ?", journeyID).
+			Update("live_version_id", version.ID).Error; err != nil {
+			return err
+		}
+		published = true
+		return nil
+	})
+	return published, err
+}
+
+func (r *versionRepository) GetByID(id string) (*models.JourneyVersion, error) {
+	var version models.JourneyVersion
+	if err := r.db.First(&version, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &version, nil
+}
+
+// GetByJourneyID lists a journey's versions, newest first, without snapshots
+// GetAll returns every version of every journey, snapshots included
+func (r *versionRepository) GetAll() ([]models.JourneyVersion, error) {
+	var versions []models.JourneyVersion
+	if err := r.db.Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *versionRepository) GetByJourneyID(journeyID string) ([]models.JourneyVersion, error) {
+	var versions []models.JourneyVersion
+	if err := r.db.Omit("snapshot").
+		Where("journey_id = ?", journeyID).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// GetPin returns the learner's pinned version of a journey, with the version
+func (r *versionRepository) GetPin(userID, journeyID string) (*models.LearnerJourneyPin, error) {
+	var pin models.LearnerJourneyPin
+	if err := r.db.Preload("Version").
+		Where("user_id = ? AND journey_id = ?", userID, journeyID).
+		First(&pin).Error; err != nil {
+		return nil, err
+	}
+	return &pin, nil
+}
+
+// UpsertPin creates the learner's pin or moves it to pin.VersionID
+func (r *versionRepository) UpsertPin(pin *models.LearnerJourneyPin) error {
+	return r.db.Omit("Version").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "journey_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"version_id", "updated_at"}),
+	}).Create(pin).Error
+}
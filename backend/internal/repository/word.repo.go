@@ -7,8 +7,13 @@ import (
 
 type WordRepository interface {
 	Create(word *models.Word) error
+	AppendBatch(scenarioID string, words []models.Word) error
+	Reorder(scenarioID string, ids []string) error
+	GetJourneyID(id string) (string, error)
 	GetByID(id string) (*models.Word, error)
+	GetByIDs(ids []string) ([]models.Word, error)
 	GetByScenarioID(scenarioID string) ([]models.Word, error)
+	GetByJourneyID(journeyID string) ([]models.Word, error)
 	Update(word *models.Word) error
 	Delete(id string) error
 }
@@ -21,8 +26,42 @@ func NewWordRepository(db *gorm.DB) WordRepository {
 	return &wordRepository{db: db}
 }
 
+// Create inserts the word, placing it after the scenario's last word when no
+// DisplayOrder is given
 func (r *wordRepository) Create(word *models.Word) error {
-	return r.db.Create(word).Error
+	if word.DisplayOrder != 0 {
+		return r.db.Create(word).Error
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		next, err := nextDisplayOrder(tx, &models.Word{}, "scenario_id", word.ScenarioID)
+		if err != nil {
+			return err
+		}
+		word.DisplayOrder = next
+		return tx.Create(word).Error
+	})
+}
+
+// AppendBatch inserts the words in one transaction, numbering their
+// DisplayOrder after the scenario's current last word
+func (r *wordRepository) AppendBatch(scenarioID string, words []models.Word) error {
+	if len(words) == 0 {
+		return nil
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		next, err := nextDisplayOrder(tx, &models.Word{}, "scenario_id", scenarioID)
+		if err != nil {
+			return err
+		}
+
+		for i := range words {
+			words[i].ScenarioID = scenarioID
+			words[i].DisplayOrder = next + i
+		}
+
+		return tx.Create(&words).Error
+	})
 }
 
 func (r *wordRepository) GetByID(id string) (*models.Word, error) {
@@ -33,6 +72,17 @@ func (r *wordRepository) GetByID(id string) (*models.Word, error) {
 	return &word, nil
 }
 
+func (r *wordRepository) GetByIDs(ids []string) ([]models.Word, error) {
+	var words []models.Word
+	if len(ids) == 0 {
+		return words, nil
+	}
+	if err := r.db.Where("id IN ?", ids).Find(&words).Error; err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
 func (r *wordRepository) GetByScenarioID(scenarioID string) ([]models.Word, error) {
 	var words []models.Word
 	if err := r.db.Where("scenario_id = ?", scenarioID).Order("display_order ASC").Find(&words).Error; err != nil {
@@ -41,10 +91,48 @@ func (r *wordRepository) GetByScenarioID(scenarioID string) ([]models.Word, erro
 	return words, nil
 }
 
+func (r *wordRepository) GetByJourneyID(journeyID string) ([]models.Word, error) {
+	var words []models.Word
+	if err := r.db.Joins("JOIN scenarios ON scenarios.id = words.scenario_id AND scenarios.deleted_at IS NULL").
+		Where("scenarios.journey_id = ?", journeyID).
+		Order("scenarios.display_order ASC, words.display_order ASC").
+		Find(&words).Error; err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
 func (r *wordRepository) Update(word *models.Word) error {
 	return r.db.Save(word).Error
 }
 
+// Delete soft deletes the word and, in the same transaction, its quiz questions
 func (r *wordRepository) Delete(id string) error {
-	return r.db.Delete(&models.Word{}, "id = ?", id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return cascadeDeleteWord(tx, id, deletionTime())
+	})
+}
+
+// Reorder renumbers the scenario's words to follow ids, which must list each
+// of them exactly once; otherwise ErrOrderMismatch is returned
+func (r *wordRepository) Reorder(scenarioID string, ids []string) error {
+	return renumber(r.db, &models.Word{}, "scenario_id", scenarioID, ids)
+}
+
+// GetJourneyID returns the journey a word belongs to, even when the word has
+// since been deleted from the draft
+func (r *wordRepository) GetJourneyID(id string) (string, error) {
+	var journeyID string
+	result := r.db.Unscoped().Model(&models.Word{}).
+		Joins("JOIN scenarios ON scenarios.id = words.scenario_id").
+		Where("words.id = ?", id).
+		Limit(1).
+		Pluck("scenarios.journey_id", &journeyID)
+	if result.Error != nil {
+		return "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return journeyID, nil
 }
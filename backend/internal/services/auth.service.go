@@ -2,22 +2,62 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
+	"github.com/learng/backend/internal/mailer"
 	"github.com/learng/backend/internal/models"
 	"github.com/learng/backend/internal/repository"
 	"github.com/learng/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// User token purposes
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+const (
+	passwordResetTokenTTL     = time.Hour
+	emailVerificationTokenTTL = 48 * time.Hour
 )
 
 type AuthService struct {
-	userRepo  *repository.UserRepository
-	jwtSecret string
+	userRepo        *repository.UserRepository
+	sessionRepo     repository.SessionRepository
+	tokenRepo       repository.UserTokenRepository
+	inviteService   InviteService
+	mailer          mailer.Mailer
+	jwtSecret       string
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	appBaseURL      string
 }
 
-func NewAuthService(userRepo *repository.UserRepository, jwtSecret string) *AuthService {
+func NewAuthService(
+	userRepo *repository.UserRepository,
+	sessionRepo repository.SessionRepository,
+	tokenRepo repository.UserTokenRepository,
+	inviteService InviteService,
+	mailer mailer.Mailer,
+	jwtSecret string,
+	accessTokenTTL, refreshTokenTTL time.Duration,
+	appBaseURL string,
+) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
+		userRepo:        userRepo,
+		sessionRepo:     sessionRepo,
+		tokenRepo:       tokenRepo,
+		inviteService:   inviteService,
+		mailer:          mailer,
+		jwtSecret:       jwtSecret,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+		appBaseURL:      strings.TrimRight(appBaseURL, "/"),
 	}
 }
 
@@ -27,6 +67,7 @@ type RegisterRequest struct {
 	Password    string `json:"password"`
 	DisplayName string `json:"displayName"`
 	Role        string `json:"role"`
+	InviteCode  string `json:"inviteCode"` // Required to register as admin
 }
 
 // LoginRequest contains the data needed to log in
@@ -35,10 +76,33 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
-// AuthResponse contains the user and token after authentication
+// RefreshRequest contains the refresh token to exchange for new tokens
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// ForgotPasswordRequest contains the email of the account to recover
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest contains a password reset token and the new password
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// VerifyEmailRequest contains an email verification token
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// AuthResponse contains the user and tokens after authentication
 type AuthResponse struct {
-	User  *models.User `json:"user"`
-	Token string       `json:"token"`
+	User         *models.User `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refreshToken"`
+	ExpiresIn    int64        `json:"expiresIn"` // Access token lifetime in seconds
 }
 
 // Register creates a new user account
@@ -53,9 +117,12 @@ func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
 		return nil, errors.New(msg)
 	}
 
-	if !utils.ValidateRole(req.Role) {
+	if req.Role != "" && !utils.ValidateRole(req.Role) {
 		return nil, errors.New("invalid role")
 	}
+	if req.Role == "admin" && req.InviteCode == "" {
+		return nil, errors.New("an invite code is required to register as admin")
+	}
 
 	// Check if user already exists
 	exists, err := s.userRepo.Exists(req.Email)
@@ -66,6 +133,17 @@ func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
 		return nil, errors.New("user with this email already exists")
 	}
 
+	// The invite decides the role; without one users can only be learners
+	role := "learner"
+	var invite *models.Invite
+	if req.InviteCode != "" {
+		invite, err = s.inviteService.FindInvite(req.InviteCode, req.Role)
+		if err != nil {
+			return nil, err
+		}
+		role = invite.Role
+	}
+
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
@@ -77,26 +155,72 @@ func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
 		Email:        req.Email,
 		PasswordHash: hashedPassword,
 		DisplayName:  req.DisplayName,
-		Role:         req.Role,
+		Role:         role,
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
+	// The invite is redeemed together with creating the user, so a failed
+	// registration leaves it unused
+	if invite != nil {
+		err = s.userRepo.CreateWithInvite(user, invite.ID)
+	} else {
+		err = s.userRepo.Create(user)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrInviteUnavailable) {
+			return nil, errors.New("invalid or expired invite code")
+		}
 		return nil, err
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Role, s.jwtSecret, 24*time.Hour)
+	// A failed verification email shouldn't fail registration
+	if err := s.sendEmailVerification(user); err != nil {
+		log.Printf("Failed to send verification email to %s: %v", user.Email, err)
+	}
+
+	return s.startSession(user)
+}
+
+// EnsureBootstrapAdmin creates the first admin account when there are no
+// users yet, so a fresh install can be administered without an invite. It
+// does nothing if email is empty or any user exists.
+func (s *AuthService) EnsureBootstrapAdmin(email, password string) error {
+	if email == "" {
+		return nil
+	}
+
+	count, err := s.userRepo.Count()
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if count > 0 {
+		return nil
 	}
 
-	// Don't return password hash
-	user.PasswordHash = ""
+	if !utils.ValidateEmail(email) {
+		return errors.New("invalid bootstrap admin email")
+	}
+	if valid, msg := utils.ValidatePassword(password); !valid {
+		return errors.New("invalid bootstrap admin password: " + msg)
+	}
 
-	return &AuthResponse{
-		User:  user,
-		Token: token,
-	}, nil
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if err := s.userRepo.Create(&models.User{
+		Email:           email,
+		PasswordHash:    hashedPassword,
+		DisplayName:     "Admin",
+		Role:            "admin",
+		EmailVerifiedAt: &now,
+	}); err != nil {
+		return err
+	}
+
+	log.Printf("Created bootstrap admin account %s", email)
+	return nil
 }
 
 // Login authenticates a user and returns a token
@@ -121,8 +245,12 @@ func (s *AuthService) Login(req LoginRequest) (*AuthResponse, error) {
 		return nil, errors.New("invalid email or password")
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Role, s.jwtSecret, 24*time.Hour)
+	return s.startSession(user)
+}
+
+// GetUserByID retrieves a user by ID
+func (s *AuthService) GetUserByID(id string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(id)
 	if err != nil {
 		return nil, err
 	}
@@ -130,15 +258,262 @@ func (s *AuthService) Login(req LoginRequest) (*AuthResponse, error) {
 	// Don't return password hash
 	user.PasswordHash = ""
 
-	return &AuthResponse{
-		User:  user,
-		Token: token,
-	}, nil
+	return user, nil
 }
 
-// GetUserByID retrieves a user by ID
-func (s *AuthService) GetUserByID(id string) (*models.User, error) {
-	user, err := s.userRepo.GetByID(id)
+// Refresh rotates a refresh token and issues a new access token. Presenting a
+// refresh token that has already been rotated revokes the whole session.
+func (s *AuthService) Refresh(req RefreshRequest) (*AuthResponse, error) {
+	sessionID, secret, ok := strings.Cut(req.RefreshToken, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, err
+	}
+	if session.RevokedAt != nil {
+		return nil, errors.New("session has been revoked")
+	}
+
+	oldHash := utils.HashToken(secret)
+	if oldHash != session.TokenHash {
+		// An old token from this session is being replayed; assume it leaked
+		if err := s.sessionRepo.Revoke(session.ID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("refresh token reuse detected")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, errors.New("refresh token expired")
+	}
+
+	user, err := s.userRepo.GetByID(session.UserID)
+	if err != nil {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	newSecret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	rotated, err := s.sessionRepo.Rotate(session.ID, oldHash, utils.HashToken(newSecret), time.Now().Add(s.refreshTokenTTL))
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		// A concurrent request already used this token
+		if err := s.sessionRepo.Revoke(session.ID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("refresh token reuse detected")
+	}
+
+	return s.issueTokens(user, session.ID, newSecret)
+}
+
+// Logout revokes a single session
+func (s *AuthService) Logout(sessionID string) error {
+	return s.sessionRepo.Revoke(sessionID)
+}
+
+// LogoutAll revokes every session belonging to the user
+func (s *AuthService) LogoutAll(userID string) error {
+	return s.sessionRepo.RevokeAllForUser(userID)
+}
+
+// IsSessionActive reports whether the session exists, is unrevoked and unexpired
+func (s *AuthService) IsSessionActive(sessionID string) (bool, error) {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return session.RevokedAt == nil && time.Now().Before(session.ExpiresAt), nil
+}
+
+// ForgotPassword emails a password reset link if the account exists. It
+// succeeds either way so callers cannot probe which emails are registered.
+func (s *AuthService) ForgotPassword(req ForgotPasswordRequest) error {
+	if !utils.ValidateEmail(req.Email) {
+		return errors.New("invalid email format")
+	}
+
+	user, err := s.userRepo.GetByEmail(req.Email)
+	if err != nil {
+		return nil
+	}
+
+	// Only the most recent reset link stays valid
+	if err := s.tokenRepo.InvalidateForUser(user.ID, TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	token, err := s.createUserToken(user.ID, TokenPurposePasswordReset, passwordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.appBaseURL + "/reset-password?token=" + url.QueryEscape(token)
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your learng password",
+		Body: fmt.Sprintf("We received a request to reset your password.\n\n"+
+			"Open this link within %d minutes to choose a new password:\n%s\n\n"+
+			"If you didn't ask for this, you can ignore this email.", int(passwordResetTokenTTL.Minutes()), link),
+	})
+}
+
+// ResetPassword sets a new password using a reset token and signs the user
+// out of every session
+func (s *AuthService) ResetPassword(req ResetPasswordRequest) error {
+	valid, msg := utils.ValidatePassword(req.Password)
+	if !valid {
+		return errors.New(msg)
+	}
+
+	token, err := s.consumeUserToken(TokenPurposePasswordReset, req.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return errors.New("invalid or expired token")
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return err
+	}
+	user.PasswordHash = hashedPassword
+
+	// Receiving the reset email also proves the address
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.userRepo.Update(user); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeAllForUser(user.ID)
+}
+
+// VerifyEmail marks the user's email as verified using a verification token
+func (s *AuthService) VerifyEmail(req VerifyEmailRequest) error {
+	token, err := s.consumeUserToken(TokenPurposeEmailVerification, req.Token)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		return errors.New("invalid or expired token")
+	}
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := s.userRepo.Update(user); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *AuthService) sendEmailVerification(user *models.User) error {
+	token, err := s.createUserToken(user.ID, TokenPurposeEmailVerification, emailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.appBaseURL + "/verify-email?token=" + url.QueryEscape(token)
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your learng email address",
+		Body:    "Welcome to learng!\n\nPlease confirm your email address by opening this link:\n" + link,
+	})
+}
+
+// createUserToken stores the hash of a new one-time token and returns the token
+func (s *AuthService) createUserToken(userID, purpose string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.tokenRepo.Create(&models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// consumeUserToken validates a one-time token and marks it used
+func (s *AuthService) consumeUserToken(purpose, token string) (*models.UserToken, error) {
+	if token == "" {
+		return nil, errors.New("token is required")
+	}
+
+	userToken, err := s.tokenRepo.GetByHash(purpose, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid or expired token")
+		}
+		return nil, err
+	}
+	if userToken.UsedAt != nil || time.Now().After(userToken.ExpiresAt) {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	used, err := s.tokenRepo.MarkUsed(userToken.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	return userToken, nil
+}
+
+// startSession creates a new session for the user and issues its first tokens
+func (s *AuthService) startSession(user *models.User) (*AuthResponse, error) {
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.Session{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(secret),
+		ExpiresAt: time.Now().Add(s.refreshTokenTTL),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session.ID, secret)
+}
+
+// issueTokens signs an access token for the session and pairs it with the
+// refresh token, formatted as "<sessionID>.<secret>"
+func (s *AuthService) issueTokens(user *models.User, sessionID, secret string) (*AuthResponse, error) {
+	token, err := utils.GenerateToken(user.ID, user.Role, sessionID, s.jwtSecret, s.accessTokenTTL)
 	if err != nil {
 		return nil, err
 	}
@@ -146,5 +521,10 @@ func (s *AuthService) GetUserByID(id string) (*models.User, error) {
 	// Don't return password hash
 	user.PasswordHash = ""
 
-	return user, nil
+	return &AuthResponse{
+		User:         user,
+		Token:        token,
+		RefreshToken: sessionID + "." + secret,
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+	}, nil
 }
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/learng/backend/internal/generation"
+	"github.com/learng/backend/internal/media"
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// ErrGenerationFailed wraps a provider's failure, or output that is not a
+// usable media file
+var ErrGenerationFailed = errors.New("generation failed")
+
+// GenerateMediaRequest selects the media to generate for a word
+type GenerateMediaRequest struct {
+	Image bool `json:"image"`
+	Audio bool `json:"audio"`
+}
+
+type GenerationService interface {
+	GenerateWordMedia(userID, wordID string, req GenerateMediaRequest) (*models.Word, error)
+}
+
+type generationService struct {
+	wordRepo          repository.WordRepository
+	scenarioRepo      repository.ScenarioRepository
+	journeyRepo       repository.JourneyRepository
+	mediaService      MediaService
+	images            generation.ImageGenerator
+	speech            generation.SpeechSynthesizer
+	maxImageDimension int
+}
+
+func NewGenerationService(
+	wordRepo repository.WordRepository,
+	scenarioRepo repository.ScenarioRepository,
+	journeyRepo repository.JourneyRepository,
+	mediaService MediaService,
+	images generation.ImageGenerator,
+	speech generation.SpeechSynthesizer,
+	maxImageDimension int,
+) GenerationService {
+	return &generationService{
+		wordRepo:          wordRepo,
+		scenarioRepo:      scenarioRepo,
+		journeyRepo:       journeyRepo,
+		mediaService:      mediaService,
+		images:            images,
+		speech:            speech,
+		maxImageDimension: maxImageDimension,
+	}
+}
+
+// GenerateWordMedia generates an image of the word, a recording of it, or
+// both, stores them as assets like uploads and links them to the word. The
+// word's generation method records which of its media were generated.
+func (s *generationService) GenerateWordMedia(userID, wordID string, req GenerateMediaRequest) (*models.Word, error) {
+	if !req.Image && !req.Audio {
+		return nil, errors.New("image or audio must be requested")
+	}
+
+	word, err := s.wordRepo.GetByID(wordID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("word not found")
+		}
+		return nil, err
+	}
+	language, err := s.targetLanguage(word)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Image {
+		data, err := s.images.GenerateImage(generation.ImageRequest{
+			Word:     word.TargetText,
+			Meaning:  word.SourceText,
+			Language: language,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: image: %v", ErrGenerationFailed, err)
+		}
+		asset, err := s.save(userID, data, media.KindImage)
+		if err != nil {
+			return nil, err
+		}
+		word.ImageURL, word.ImageAssetID = &asset.URL, &asset.ID
+	}
+
+	if req.Audio {
+		data, err := s.speech.Synthesize(generation.SpeechRequest{
+			Text:     word.TargetText,
+			Language: language,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%w: audio: %v", ErrGenerationFailed, err)
+		}
+		asset, err := s.save(userID, data, media.KindAudio)
+		if err != nil {
+			return nil, err
+		}
+		word.AudioURL, word.AudioAssetID = &asset.URL, &asset.ID
+	}
+
+	word.GenerationMethod = mergeGenerationMethod(word.GenerationMethod, req)
+	if err := s.wordRepo.Update(word); err != nil {
+		return nil, err
+	}
+	return word, nil
+}
+
+// save validates generated media as an upload would be and stores it
+func (s *generationService) save(userID string, data []byte, kind string) (*models.MediaAsset, error) {
+	info, err := media.Validate(data, kind, s.maxImageDimension)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrGenerationFailed, kind, err)
+	}
+	asset, _, err := s.mediaService.Save(userID, data, info)
+	if err != nil {
+		if errors.Is(err, media.ErrMalformed) || errors.Is(err, media.ErrTooLong) || errors.Is(err, media.ErrSilent) || errors.Is(err, media.ErrNoDecoder) {
+			return nil, fmt.Errorf("%w: %s: %v", ErrGenerationFailed, kind, err)
+		}
+		return nil, err
+	}
+	return asset, nil
+}
+
+// targetLanguage returns the language of the word's journey
+func (s *generationService) targetLanguage(word *models.Word) (string, error) {
+	scenario, err := s.scenarioRepo.GetByID(word.ScenarioID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("scenario not found")
+		}
+		return "", err
+	}
+	journey, err := s.journeyRepo.GetByID(scenario.JourneyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("journey not found")
+		}
+		return "", err
+	}
+	return journey.TargetLanguage, nil
+}
+
+// mergeGenerationMethod adds the newly generated media to a word's method.
+// Media generated earlier stay counted.
+func mergeGenerationMethod(method string, req GenerateMediaRequest) string {
+	image := req.Image || method == "ai_image" || method == "ai_both"
+	audio := req.Audio || method == "ai_audio" || method == "ai_both"
+	switch {
+	case image && audio:
+		return "ai_both"
+	case image:
+		return "ai_image"
+	case audio:
+		return "ai_audio"
+	}
+	return "manual"
+}
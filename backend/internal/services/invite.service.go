@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/repository"
+	"github.com/learng/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultInviteTTL = 7 * 24 * time.Hour
+	maxInviteTTL     = 90 * 24 * time.Hour
+)
+
+// CreateInviteRequest contains the options for a new invite code
+type CreateInviteRequest struct {
+	Role           string `json:"role"`
+	MaxUses        int    `json:"maxUses"`
+	ExpiresInHours int    `json:"expiresInHours"`
+}
+
+// InviteResponse is a newly created invite together with its code, which is
+// only ever returned here
+type InviteResponse struct {
+	*models.Invite
+	Code string `json:"code"`
+}
+
+type InviteService interface {
+	CreateInvite(createdBy string, req CreateInviteRequest) (*InviteResponse, error)
+	GetInvites() ([]models.Invite, error)
+	RevokeInvite(id string) error
+	FindInvite(code, role string) (*models.Invite, error)
+}
+
+type inviteService struct {
+	inviteRepo repository.InviteRepository
+}
+
+func NewInviteService(inviteRepo repository.InviteRepository) InviteService {
+	return &inviteService{inviteRepo: inviteRepo}
+}
+
+func (s *inviteService) CreateInvite(createdBy string, req CreateInviteRequest) (*InviteResponse, error) {
+	if req.Role == "" {
+		req.Role = "learner"
+	}
+	if !utils.ValidateRole(req.Role) {
+		return nil, errors.New("invalid role")
+	}
+	if req.MaxUses < 0 {
+		return nil, errors.New("max uses must be positive")
+	}
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+
+	ttl := defaultInviteTTL
+	if req.ExpiresInHours < 0 {
+		return nil, errors.New("expiry must be positive")
+	}
+	if req.ExpiresInHours > 0 {
+		ttl = time.Duration(req.ExpiresInHours) * time.Hour
+	}
+	if ttl > maxInviteTTL {
+		return nil, errors.New("expiry must be at most 90 days")
+	}
+
+	code, err := utils.GenerateRandomToken(12)
+	if err != nil {
+		return nil, err
+	}
+
+	invite := &models.Invite{
+		CodeHash:  utils.HashToken(code),
+		Role:      req.Role,
+		MaxUses:   req.MaxUses,
+		ExpiresAt: time.Now().Add(ttl),
+		CreatedBy: createdBy,
+	}
+	if err := s.inviteRepo.Create(invite); err != nil {
+		return nil, err
+	}
+
+	return &InviteResponse{Invite: invite, Code: code}, nil
+}
+
+func (s *inviteService) GetInvites() ([]models.Invite, error) {
+	return s.inviteRepo.GetAll()
+}
+
+func (s *inviteService) RevokeInvite(id string) error {
+	_, err := s.inviteRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("invite not found")
+		}
+		return err
+	}
+
+	return s.inviteRepo.Revoke(id)
+}
+
+// FindInvite returns the invite with the given code for registration, which
+// redeems it. A non-empty role must match the invite's role.
+func (s *inviteService) FindInvite(code, role string) (*models.Invite, error) {
+	invite, err := s.inviteRepo.GetByCodeHash(utils.HashToken(code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("invalid or expired invite code")
+		}
+		return nil, err
+	}
+
+	if role != "" && role != invite.Role {
+		return nil, errors.New("invite code is not valid for this role")
+	}
+
+	return invite, nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 
+	"github.com/google/uuid"
 	"github.com/learng/backend/internal/models"
 	"github.com/learng/backend/internal/repository"
 	"gorm.io/gorm"
@@ -15,17 +16,39 @@ type JourneyService interface {
 	UpdateJourney(id string, updates map[string]interface{}) (*models.Journey, error)
 	DeleteJourney(id string) error
 	GetJourneyWithScenarios(id string) (*models.Journey, error)
+	CloneJourney(id, userID string, req CloneJourneyRequest) (*models.Journey, error)
+}
+
+// CloneJourneyRequest configures a deep copy of a journey. Title and languages
+// default to the source journey's; translations and media are kept unless
+// explicitly dropped.
+type CloneJourneyRequest struct {
+	Title            string  `json:"title"`
+	Description      *string `json:"description"`
+	SourceLanguage   string  `json:"sourceLanguage"`
+	TargetLanguage   string  `json:"targetLanguage"`
+	KeepTranslations *bool   `json:"keepTranslations"`
+	KeepMedia        *bool   `json:"keepMedia"`
 }
 
 type journeyService struct {
 	journeyRepo  repository.JourneyRepository
 	scenarioRepo repository.ScenarioRepository
+	wordRepo     repository.WordRepository
+	quizRepo     repository.QuizRepository
 }
 
-func NewJourneyService(journeyRepo repository.JourneyRepository, scenarioRepo repository.ScenarioRepository) JourneyService {
+func NewJourneyService(
+	journeyRepo repository.JourneyRepository,
+	scenarioRepo repository.ScenarioRepository,
+	wordRepo repository.WordRepository,
+	quizRepo repository.QuizRepository,
+) JourneyService {
 	return &journeyService{
 		journeyRepo:  journeyRepo,
 		scenarioRepo: scenarioRepo,
+		wordRepo:     wordRepo,
+		quizRepo:     quizRepo,
 	}
 }
 
@@ -77,12 +100,9 @@ func (s *journeyService) UpdateJourney(id string, updates map[string]interface{}
 	if description, ok := updates["description"].(string); ok {
 		journey.Description = description
 	}
-	if status, ok := updates["status"].(string); ok {
-		// Validate status
-		if status != "draft" && status != "published" && status != "archived" {
-			return nil, errors.New("invalid status")
-		}
-		journey.Status = status
+	if status, ok := updates["status"].(string); ok && status != journey.Status {
+		// Status moves only through PublishService so publishing is validated
+		return nil, errors.New("status can only be changed with the publish, unpublish and archive actions")
 	}
 	if sourceLang, ok := updates["sourceLanguage"].(string); ok {
 		journey.SourceLanguage = sourceLang
@@ -130,3 +150,126 @@ func (s *journeyService) GetJourneyWithScenarios(id string) (*models.Journey, er
 
 	return journey, nil
 }
+
+// CloneJourney deep-copies a journey with its scenarios, words and quizzes into
+// a new draft owned by userID. Quiz questions whose word lost the translation
+// or media they depend on are dropped, as are quizzes left with no questions.
+func (s *journeyService) CloneJourney(id, userID string, req CloneJourneyRequest) (*models.Journey, error) {
+	source, err := s.journeyRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("journey not found")
+		}
+		return nil, err
+	}
+
+	keepTranslations := req.KeepTranslations == nil || *req.KeepTranslations
+	keepMedia := req.KeepMedia == nil || *req.KeepMedia
+
+	clone := &models.Journey{
+		ID:              uuid.New().String(),
+		Title:           req.Title,
+		Description:     source.Description,
+		SourceLanguage:  req.SourceLanguage,
+		TargetLanguage:  req.TargetLanguage,
+		Status:          "draft",
+		CreatedBy:       userID,
+		SourceJourneyID: &source.ID,
+	}
+	if clone.Title == "" {
+		clone.Title = source.Title + " (copy)"
+	}
+	if req.Description != nil {
+		clone.Description = *req.Description
+	}
+	if clone.SourceLanguage == "" {
+		clone.SourceLanguage = source.SourceLanguage
+	}
+	if clone.TargetLanguage == "" {
+		clone.TargetLanguage = source.TargetLanguage
+	}
+
+	tree, err := loadJourneyTree(s.scenarioRepo, s.wordRepo, s.quizRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	scenarios, words, quizzes := tree.Scenarios, tree.Words, tree.Quizzes
+
+	index := make(map[string]int, len(scenarios))
+	for i, scenario := range scenarios {
+		index[scenario.ID] = i
+		clone.Scenarios = append(clone.Scenarios, models.Scenario{
+			ID:           uuid.New().String(),
+			JourneyID:    clone.ID,
+			Title:        scenario.Title,
+			Description:  scenario.Description,
+			DisplayOrder: scenario.DisplayOrder,
+		})
+	}
+
+	clonedWords := make(map[string]models.Word, len(words))
+	for _, word := range words {
+		i, ok := index[word.ScenarioID]
+		if !ok {
+			continue
+		}
+		copied := models.Word{
+			ID:               uuid.New().String(),
+			ScenarioID:       clone.Scenarios[i].ID,
+			TargetText:       word.TargetText,
+			DisplayOrder:     word.DisplayOrder,
+			GenerationMethod: word.GenerationMethod,
+		}
+		if keepTranslations {
+			copied.SourceText = word.SourceText
+		}
+		if keepMedia {
+			copied.ImageURL = word.ImageURL
+			copied.AudioURL = word.AudioURL
+			copied.ImageAssetID = word.ImageAssetID
+			copied.AudioAssetID = word.AudioAssetID
+		} else {
+			copied.GenerationMethod = "manual"
+		}
+		clonedWords[word.ID] = copied
+		clone.Scenarios[i].Words = append(clone.Scenarios[i].Words, copied)
+	}
+
+	for _, quiz := range quizzes {
+		i, ok := index[quiz.ScenarioID]
+		if !ok {
+			continue
+		}
+		copied := models.Quiz{
+			ID:            uuid.New().String(),
+			ScenarioID:    clone.Scenarios[i].ID,
+			Title:         quiz.Title,
+			PassThreshold: quiz.PassThreshold,
+		}
+		for _, question := range quiz.Questions {
+			word, ok := clonedWords[question.WordID]
+			if !ok || !supportsQuestionType(word, question.QuestionType) {
+				continue
+			}
+			copied.Questions = append(copied.Questions, models.QuizQuestion{
+				QuizID:        copied.ID,
+				WordID:        word.ID,
+				QuestionType:  question.QuestionType,
+				QuestionText:  question.QuestionText,
+				CorrectAnswer: question.CorrectAnswer,
+				Options:       question.Options,
+				DisplayOrder:  len(copied.Questions) + 1,
+			})
+		}
+		if len(copied.Questions) == 0 {
+			continue
+		}
+		clone.Scenarios[i].Quizzes = append(clone.Scenarios[i].Quizzes, copied)
+	}
+
+	if err := s.journeyRepo.CreateTree(clone); err != nil {
+		return nil, err
+	}
+
+	return clone, nil
+}
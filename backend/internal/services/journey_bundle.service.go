@@ -0,0 +1,553 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/learng/backend/internal/media"
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/repository"
+	"github.com/learng/backend/internal/storage"
+	"github.com/learng/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// BundleVersion is the manifest format written by ExportJourney. Imports
+// accept any version up to and including this one.
+const BundleVersion = 1
+
+// ErrInvalidBundle is returned when an upload is not a readable journey bundle
+var ErrInvalidBundle = errors.New("invalid journey bundle")
+
+const (
+	bundleManifestName = "manifest.json"
+	bundleMediaDir     = "media/"
+	maxManifestSize    = 10 * 1024 * 1024 // 10MB
+)
+
+// Import conflict kinds
+const (
+	ConflictTitleExists      = "title_exists"
+	ConflictMissingMedia     = "missing_media"
+	ConflictUnsupportedMedia = "unsupported_media"
+	ConflictUnknownWord      = "unknown_word"
+)
+
+// bundleMediaKinds maps an upload subdirectory to the kind of media it holds
+var bundleMediaKinds = map[string]string{
+	"images": media.KindImage,
+	"audio":  media.KindAudio,
+}
+
+// JourneyBundle is the manifest stored as manifest.json in an export zip
+type JourneyBundle struct {
+	Version    int           `json:"version"`
+	ExportedAt time.Time     `json:"exportedAt"`
+	Journey    BundleJourney `json:"journey"`
+	Media      []BundleMedia `json:"media"`
+}
+
+type BundleJourney struct {
+	ID             string           `json:"id"`
+	Title          string           `json:"title"`
+	Description    string           `json:"description"`
+	SourceLanguage string           `json:"sourceLanguage"`
+	TargetLanguage string           `json:"targetLanguage"`
+	Scenarios      []BundleScenario `json:"scenarios"`
+}
+
+type BundleScenario struct {
+	ID           string       `json:"id"`
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	DisplayOrder int          `json:"displayOrder"`
+	Words        []BundleWord `json:"words"`
+	Quizzes      []BundleQuiz `json:"quizzes"`
+}
+
+type BundleWord struct {
+	ID               string  `json:"id"`
+	TargetText       string  `json:"targetText"`
+	SourceText       string  `json:"sourceText"`
+	DisplayOrder     int     `json:"displayOrder"`
+	ImageURL         *string `json:"imageUrl"`
+	AudioURL         *string `json:"audioUrl"`
+	GenerationMethod string  `json:"generationMethod"`
+}
+
+type BundleQuiz struct {
+	ID            string               `json:"id,omitempty"`
+	Title         string               `json:"title"`
+	PassThreshold float64              `json:"passThreshold"`
+	Questions     []BundleQuizQuestion `json:"questions"`
+}
+
+type BundleQuizQuestion struct {
+	ID            string `json:"id,omitempty"`
+	WordID        string `json:"wordId"` // References BundleWord.ID
+	QuestionType  string `json:"questionType"`
+	QuestionText  string `json:"questionText"`
+	CorrectAnswer string `json:"correctAnswer"`
+	Options       string `json:"options"`
+	DisplayOrder  int    `json:"displayOrder"`
+}
+
+// BundleMedia links a media URL used in the manifest to its file in the zip
+type BundleMedia struct {
+	URL  string `json:"url"`  // e.g. /uploads/images/<file>.png
+	Path string `json:"path"` // e.g. media/images/<file>.png
+}
+
+// JourneyImportResult summarizes an imported bundle
+type JourneyImportResult struct {
+	Journey   *models.Journey  `json:"journey"`
+	Scenarios int              `json:"scenarios"`
+	Words     int              `json:"words"`
+	Quizzes   int              `json:"quizzes"`
+	Media     int              `json:"media"`
+	Conflicts []ImportConflict `json:"conflicts"`
+}
+
+// ImportConflict is something in the bundle that could not be imported as-is
+type ImportConflict struct {
+	Type    string `json:"type"`
+	Ref     string `json:"ref,omitempty"`
+	Message string `json:"message"`
+}
+
+type JourneyBundleService interface {
+	ExportJourney(id string) (*JourneyBundle, error)
+	WriteBundle(w io.Writer, bundle *JourneyBundle) error
+	ImportJourney(userID string, r io.ReaderAt, size int64) (*JourneyImportResult, error)
+}
+
+type journeyBundleService struct {
+	journeyRepo  repository.JourneyRepository
+	scenarioRepo repository.ScenarioRepository
+	wordRepo     repository.WordRepository
+	quizRepo     repository.QuizRepository
+	mediaService MediaService
+	store        storage.Storage
+	maxImageSize int64
+	maxAudioSize int64
+	maxImageDim  int
+}
+
+func NewJourneyBundleService(
+	journeyRepo repository.JourneyRepository,
+	scenarioRepo repository.ScenarioRepository,
+	wordRepo repository.WordRepository,
+	quizRepo repository.QuizRepository,
+	mediaService MediaService,
+	store storage.Storage,
+	maxImageSize, maxAudioSize int64,
+	maxImageDimension int,
+) JourneyBundleService {
+	return &journeyBundleService{
+		journeyRepo:  journeyRepo,
+		scenarioRepo: scenarioRepo,
+		wordRepo:     wordRepo,
+		quizRepo:     quizRepo,
+		mediaService: mediaService,
+		store:        store,
+		maxImageSize: maxImageSize,
+		maxAudioSize: maxAudioSize,
+		maxImageDim:  maxImageDimension,
+	}
+}
+
+// ExportJourney builds the manifest for a journey and lists the stored media
+// files it references. Media that is missing from storage is left out of the list,
+// which the importer reports as a conflict.
+func (s *journeyBundleService) ExportJourney(id string) (*JourneyBundle, error) {
+	journey, err := s.journeyRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("journey not found")
+		}
+		return nil, err
+	}
+
+	tree, err := loadJourneyTree(s.scenarioRepo, s.wordRepo, s.quizRepo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &JourneyBundle{
+		Version:    BundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Journey:    snapshotJourney(journey, tree),
+		Media:      []BundleMedia{},
+	}
+
+	seenMedia := make(map[string]bool)
+	addMedia := func(url *string) {
+		if url == nil || seenMedia[*url] {
+			return
+		}
+		seenMedia[*url] = true
+		if key, ok := s.storedMedia(*url); ok {
+			bundle.Media = append(bundle.Media, BundleMedia{URL: *url, Path: bundleMediaDir + key})
+		}
+	}
+	for _, scenario := range bundle.Journey.Scenarios {
+		for _, word := range scenario.Words {
+			addMedia(word.ImageURL)
+			addMedia(word.AudioURL)
+		}
+	}
+
+	return bundle, nil
+}
+
+// WriteBundle writes the manifest and its media files as a zip archive
+func (s *journeyBundleService) WriteBundle(w io.Writer, bundle *JourneyBundle) error {
+	zw := zip.NewWriter(w)
+
+	manifest, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     bundleManifestName,
+		Method:   zip.Deflate,
+		Modified: bundle.ExportedAt,
+	})
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(manifest)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return err
+	}
+
+	for _, media := range bundle.Media {
+		key, ok := s.storedMedia(media.URL)
+		if !ok {
+			continue
+		}
+		if err := s.addObjectToZip(zw, key, media.Path, bundle.ExportedAt); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+// ImportJourney recreates a bundled journey as a draft owned by userID. Every
+// record gets a fresh ID and media files are copied under new names, so a
+// bundle can be imported any number of times.
+func (s *journeyBundleService) ImportJourney(userID string, r io.ReaderAt, size int64) (*JourneyImportResult, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not a valid zip file", ErrInvalidBundle)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	bundle, err := readBundleManifest(files[bundleManifestName])
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Journey.Title == "" || bundle.Journey.SourceLanguage == "" || bundle.Journey.TargetLanguage == "" {
+		return nil, fmt.Errorf("%w: manifest journey is missing title or languages", ErrInvalidBundle)
+	}
+
+	result := &JourneyImportResult{Conflicts: []ImportConflict{}}
+
+	existing, err := s.journeyRepo.GetByCreator(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, journey := range existing {
+		if strings.EqualFold(journey.Title, bundle.Journey.Title) {
+			result.Conflicts = append(result.Conflicts, ImportConflict{
+				Type:    ConflictTitleExists,
+				Ref:     journey.ID,
+				Message: fmt.Sprintf("you already have a journey titled %q", journey.Title),
+			})
+			break
+		}
+	}
+
+	// Copy media first so words can point at the new URLs
+	bundled := make(map[string]string, len(bundle.Media))
+	for _, media := range bundle.Media {
+		bundled[media.URL] = media.Path
+	}
+	var created []*models.MediaAsset
+	cleanup := func() {
+		for _, asset := range created {
+			s.mediaService.Remove(asset)
+		}
+	}
+	type importedMedia struct {
+		url     *string
+		assetID *string
+	}
+	rewritten := make(map[string]importedMedia)
+	rewriteURL := func(url *string) (*string, *string, error) {
+		if url == nil || *url == "" {
+			return url, nil, nil
+		}
+		if imported, ok := rewritten[*url]; ok {
+			return imported.url, imported.assetID, nil
+		}
+		zipPath, ok := bundled[*url]
+		if _, stored := storage.KeyFromURL(s.store, *url); !ok && !stored {
+			// External URLs are kept as they are
+			rewritten[*url] = importedMedia{url: url}
+			return url, nil, nil
+		}
+
+		f := files[zipPath]
+		if !ok || f == nil {
+			result.Conflicts = append(result.Conflicts, ImportConflict{
+				Type:    ConflictMissingMedia,
+				Ref:     *url,
+				Message: "media file is not included in the bundle and was dropped",
+			})
+			rewritten[*url] = importedMedia{}
+			return nil, nil, nil
+		}
+
+		asset, isNew, err := s.extractMedia(userID, f)
+		if err != nil {
+			if errors.Is(err, errUnsupportedMedia) {
+				result.Conflicts = append(result.Conflicts, ImportConflict{
+					Type:    ConflictUnsupportedMedia,
+					Ref:     *url,
+					Message: err.Error(),
+				})
+				rewritten[*url] = importedMedia{}
+				return nil, nil, nil
+			}
+			return nil, nil, err
+		}
+		if isNew {
+			created = append(created, asset)
+		}
+		result.Media++
+		rewritten[*url] = importedMedia{url: &asset.URL, assetID: &asset.ID}
+		return &asset.URL, &asset.ID, nil
+	}
+
+	journey := &models.Journey{
+		ID:             uuid.New().String(),
+		Title:          bundle.Journey.Title,
+		Description:    bundle.Journey.Description,
+		SourceLanguage: bundle.Journey.SourceLanguage,
+		TargetLanguage: bundle.Journey.TargetLanguage,
+		Status:         "draft",
+		CreatedBy:      userID,
+	}
+
+	for i, bs := range bundle.Journey.Scenarios {
+		scenario := models.Scenario{
+			ID:           uuid.New().String(),
+			JourneyID:    journey.ID,
+			Title:        bs.Title,
+			Description:  bs.Description,
+			DisplayOrder: bs.DisplayOrder,
+		}
+		if scenario.DisplayOrder == 0 {
+			scenario.DisplayOrder = i + 1
+		}
+
+		wordIDs := make(map[string]string, len(bs.Words))
+		for j, bw := range bs.Words {
+			imageURL, imageAssetID, err := rewriteURL(bw.ImageURL)
+			if err != nil {
+				cleanup()
+				return nil, err
+			}
+			audioURL, audioAssetID, err := rewriteURL(bw.AudioURL)
+			if err != nil {
+				cleanup()
+				return nil, err
+			}
+			word := models.Word{
+				ID:               uuid.New().String(),
+				ScenarioID:       scenario.ID,
+				TargetText:       bw.TargetText,
+				SourceText:       bw.SourceText,
+				DisplayOrder:     bw.DisplayOrder,
+				ImageURL:         imageURL,
+				AudioURL:         audioURL,
+				ImageAssetID:     imageAssetID,
+				AudioAssetID:     audioAssetID,
+				GenerationMethod: bw.GenerationMethod,
+			}
+			if word.DisplayOrder == 0 {
+				word.DisplayOrder = j + 1
+			}
+			wordIDs[bw.ID] = word.ID
+			scenario.Words = append(scenario.Words, word)
+		}
+
+		for _, bq := range bs.Quizzes {
+			quiz := models.Quiz{
+				ID:            uuid.New().String(),
+				ScenarioID:    scenario.ID,
+				Title:         bq.Title,
+				PassThreshold: bq.PassThreshold,
+			}
+			for _, question := range bq.Questions {
+				wordID, ok := wordIDs[question.WordID]
+				if !ok {
+					result.Conflicts = append(result.Conflicts, ImportConflict{
+						Type:    ConflictUnknownWord,
+						Ref:     question.WordID,
+						Message: fmt.Sprintf("question in quiz %q references a word not in its scenario and was dropped", bq.Title),
+					})
+					continue
+				}
+				quiz.Questions = append(quiz.Questions, models.QuizQuestion{
+					QuizID:        quiz.ID,
+					WordID:        wordID,
+					QuestionType:  question.QuestionType,
+					QuestionText:  question.QuestionText,
+					CorrectAnswer: question.CorrectAnswer,
+					Options:       question.Options,
+					DisplayOrder:  question.DisplayOrder,
+				})
+			}
+			if len(quiz.Questions) == 0 {
+				continue
+			}
+			scenario.Quizzes = append(scenario.Quizzes, quiz)
+			result.Quizzes++
+		}
+
+		result.Words += len(scenario.Words)
+		journey.Scenarios = append(journey.Scenarios, scenario)
+	}
+	result.Scenarios = len(journey.Scenarios)
+
+	if err := s.journeyRepo.CreateTree(journey); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	result.Journey = journey
+	return result, nil
+}
+
+var errUnsupportedMedia = errors.New("unsupported media file")
+
+// storedMedia resolves a media URL to the key of an existing object under
+// the images or audio prefix. Only those two prefixes are exported; for an
+// image set that is its full variant, from which import regenerates the rest.
+func (s *journeyBundleService) storedMedia(url string) (string, bool) {
+	key, ok := storage.KeyFromURL(s.store, url)
+	if !ok {
+		return "", false
+	}
+	subdir, name, _ := strings.Cut(key, "/")
+	if _, ok := bundleMediaKinds[subdir]; !ok || name == "" {
+		return "", false
+	}
+	if _, err := s.store.Stat(key); err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// extractMedia checks a bundled media file by its content and saves it as an
+// asset owned by userID, reporting whether the asset is new. A file that is
+// already stored reuses its existing asset.
+func (s *journeyBundleService) extractMedia(userID string, f *zip.File) (*models.MediaAsset, bool, error) {
+	rel, ok := strings.CutPrefix(path.Clean(f.Name), bundleMediaDir)
+	if !ok {
+		return nil, false, fmt.Errorf("%w: %s", errUnsupportedMedia, f.Name)
+	}
+	subdir, name, _ := strings.Cut(rel, "/")
+	kind, ok := bundleMediaKinds[subdir]
+	if !ok || name == "" {
+		return nil, false, fmt.Errorf("%w: %s", errUnsupportedMedia, f.Name)
+	}
+
+	maxSize := s.maxImageSize
+	if kind == media.KindAudio {
+		maxSize = s.maxAudioSize
+	}
+	if f.UncompressedSize64 > uint64(maxSize) {
+		return nil, false, fmt.Errorf("%w: %s exceeds %s", errUnsupportedMedia, f.Name, utils.FormatSize(maxSize))
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, f.Name, err)
+	}
+	defer src.Close()
+
+	// The declared size can lie, so cap what is actually read too
+	data, err := io.ReadAll(io.LimitReader(src, maxSize+1))
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s: %v", ErrInvalidBundle, f.Name, err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, false, fmt.Errorf("%w: %s exceeds %s", errUnsupportedMedia, f.Name, utils.FormatSize(maxSize))
+	}
+
+	// Trust the content, not the name it was bundled under
+	info, err := media.Validate(data, kind, s.maxImageDim)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s: %v", errUnsupportedMedia, f.Name, err)
+	}
+
+	asset, isNew, err := s.mediaService.Save(userID, data, info)
+	if err != nil {
+		if errors.Is(err, media.ErrMalformed) || errors.Is(err, media.ErrTooLong) || errors.Is(err, media.ErrSilent) || errors.Is(err, media.ErrNoDecoder) {
+			return nil, false, fmt.Errorf("%w: %s: %v", errUnsupportedMedia, f.Name, err)
+		}
+		return nil, false, err
+	}
+	return asset, isNew, nil
+}
+
+func readBundleManifest(f *zip.File) (*JourneyBundle, error) {
+	if f == nil {
+		return nil, fmt.Errorf("%w: no %s", ErrInvalidBundle, bundleManifestName)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	defer rc.Close()
+
+	var bundle JourneyBundle
+	if err := json.NewDecoder(io.LimitReader(rc, maxManifestSize)).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("%w: invalid manifest: %v", ErrInvalidBundle, err)
+	}
+	if bundle.Version < 1 || bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d (max %d)", ErrInvalidBundle, bundle.Version, BundleVersion)
+	}
+	return &bundle, nil
+}
+
+func (s *journeyBundleService) addObjectToZip(zw *zip.Writer, key, name string, modified time.Time) error {
+	src, _, err := s.store.Get(key)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zw.CreateHeader(&zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/repository"
+)
+
+// journeyTree is the editable content of a journey: its live scenarios,
+// words and quizzes with their questions
+type journeyTree struct {
+	Scenarios []models.Scenario
+	Words     []models.Word
+	Quizzes   []models.Quiz
+}
+
+func loadJourneyTree(
+	scenarioRepo repository.ScenarioRepository,
+	wordRepo repository.WordRepository,
+	quizRepo repository.QuizRepository,
+	journeyID string,
+) (*journeyTree, error) {
+	scenarios, err := scenarioRepo.GetByJourneyID(journeyID)
+	if err != nil {
+		return nil, err
+	}
+	words, err := wordRepo.GetByJourneyID(journeyID)
+	if err != nil {
+		return nil, err
+	}
+	scenarioIDs := make([]string, len(scenarios))
+	for i, scenario := range scenarios {
+		scenarioIDs[i] = scenario.ID
+	}
+	quizzes, err := quizRepo.GetByScenarioIDsWithQuestions(scenarioIDs)
+	if err != nil {
+		return nil, err
+	}
+	return &journeyTree{Scenarios: scenarios, Words: words, Quizzes: quizzes}, nil
+}
+
+// snapshotJourney converts a journey and its tree to the bundle format, which
+// is shared by exports and published versions
+func snapshotJourney(journey *models.Journey, tree *journeyTree) BundleJourney {
+	snapshot := BundleJourney{
+		ID:             journey.ID,
+		Title:          journey.Title,
+		Description:    journey.Description,
+		SourceLanguage: journey.SourceLanguage,
+		TargetLanguage: journey.TargetLanguage,
+		Scenarios:      make([]BundleScenario, 0, len(tree.Scenarios)),
+	}
+
+	index := make(map[string]int, len(tree.Scenarios))
+	for _, scenario := range tree.Scenarios {
+		index[scenario.ID] = len(snapshot.Scenarios)
+		snapshot.Scenarios = append(snapshot.Scenarios, BundleScenario{
+			ID:           scenario.ID,
+			Title:        scenario.Title,
+			Description:  scenario.Description,
+			DisplayOrder: scenario.DisplayOrder,
+			Words:        []BundleWord{},
+			Quizzes:      []BundleQuiz{},
+		})
+	}
+
+	for _, word := range tree.Words {
+		i, ok := index[word.ScenarioID]
+		if !ok {
+			continue
+		}
+		snapshot.Scenarios[i].Words = append(snapshot.Scenarios[i].Words, BundleWord{
+			ID:               word.ID,
+			TargetText:       word.TargetText,
+			SourceText:       word.SourceText,
+			DisplayOrder:     word.DisplayOrder,
+			ImageURL:         word.ImageURL,
+			AudioURL:         word.AudioURL,
+			GenerationMethod: word.GenerationMethod,
+		})
+	}
+
+	for _, quiz := range tree.Quizzes {
+		i, ok := index[quiz.ScenarioID]
+		if !ok {
+			continue
+		}
+		bundleQuiz := BundleQuiz{
+			ID:            quiz.ID,
+			Title:         quiz.Title,
+			PassThreshold: quiz.PassThreshold,
+			Questions:     make([]BundleQuizQuestion, 0, len(quiz.Questions)),
+		}
+		for _, question := range quiz.Questions {
+			bundleQuiz.Questions = append(bundleQuiz.Questions, BundleQuizQuestion{
+				ID:            question.ID,
+				WordID:        question.WordID,
+				QuestionType:  question.QuestionType,
+				QuestionText:  question.QuestionText,
+				CorrectAnswer: question.CorrectAnswer,
+				Options:       question.Options,
+				DisplayOrder:  question.DisplayOrder,
+			})
+		}
+		snapshot.Scenarios[i].Quizzes = append(snapshot.Scenarios[i].Quizzes, bundleQuiz)
+	}
+
+	return snapshot
+}
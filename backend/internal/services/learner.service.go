@@ -0,0 +1,295 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/learng/backend/internal/media"
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/repository"
+	"github.com/learng/backend/internal/storage"
+	"gorm.io/gorm"
+)
+
+// LearnerJourney is the learner-facing view of a published journey, as of
+// the version the learner is working through
+type LearnerJourney struct {
+	ID                string                   `json:"id"`
+	Title             string                   `json:"title"`
+	Description       string                   `json:"description"`
+	SourceLanguage    string                   `json:"sourceLanguage"`
+	TargetLanguage    string                   `json:"targetLanguage"`
+	Version           int                      `json:"version"`
+	LiveVersion       int                      `json:"liveVersion"`
+	UpdateAvailable   bool                     `json:"updateAvailable"`
+	ScenarioCount     int                      `json:"scenarioCount"`
+	WordCount         int64                    `json:"wordCount"`
+	CompletionPercent float64                  `json:"completionPercent"`
+	Scenarios         []LearnerScenarioSummary `json:"scenarios"`
+}
+
+// LearnerScenarioSummary is a scenario annotated with the learner's completion
+type LearnerScenarioSummary struct {
+	ID                string  `json:"id"`
+	JourneyID         string  `json:"journeyId"`
+	Title             string  `json:"title"`
+	Description       string  `json:"description"`
+	DisplayOrder      int     `json:"displayOrder"`
+	WordCount         int64   `json:"wordCount"`
+	CompletionPercent float64 `json:"completionPercent"`
+}
+
+// LearnerScenario is a scenario with its words and the learner's progress on
+// each, plus the quizzes it offers
+type LearnerScenario struct {
+	LearnerScenarioSummary
+	Words   []LearnerWord `json:"words"`
+	Quizzes []LearnerQuiz `json:"quizzes"`
+}
+
+// LearnerWord is a word card together with the learner's progress on it
+type LearnerWord struct {
+	ID           string          `json:"id"`
+	TargetText   string          `json:"targetText"`
+	SourceText   string          `json:"sourceText"`
+	DisplayOrder int             `json:"displayOrder"`
+	ImageURL     *string         `json:"imageUrl"`
+	ImageSet     *media.ImageSet `json:"imageSet,omitempty"`
+	AudioURL     *string         `json:"audioUrl"`
+	MasteryLevel string          `json:"masteryLevel"`
+	ViewCount    int             `json:"viewCount"`
+	LastViewedAt *time.Time      `json:"lastViewedAt"`
+}
+
+// LearnerQuiz identifies a quiz the learner can take
+type LearnerQuiz struct {
+	ID            string `json:"id"`
+	Title         string `json:"title"`
+	QuestionCount int    `json:"questionCount"`
+}
+
+type LearnerService interface {
+	GetPublishedJourneys(userID string, page, limit int) ([]LearnerJourney, int64, error)
+	GetPublishedScenario(userID, scenarioID string) (*LearnerScenario, error)
+	UpgradeJourney(userID, journeyID string) (*LearnerJourney, error)
+}
+
+type learnerService struct {
+	journeyRepo    repository.JourneyRepository
+	scenarioRepo   repository.ScenarioRepository
+	progressRepo   repository.ProgressRepository
+	versionService VersionService
+	signer         *storage.URLSigner
+}
+
+func NewLearnerService(
+	journeyRepo repository.JourneyRepository,
+	scenarioRepo repository.ScenarioRepository,
+	progressRepo repository.ProgressRepository,
+	versionService VersionService,
+	signer *storage.URLSigner,
+) LearnerService {
+	return &learnerService{
+		journeyRepo:    journeyRepo,
+		scenarioRepo:   scenarioRepo,
+		progressRepo:   progressRepo,
+		versionService: versionService,
+		signer:         signer,
+	}
+}
+
+func (s *learnerService) GetPublishedJourneys(userID string, page, limit int) ([]LearnerJourney, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	filters := map[string]interface{}{"status": "published"}
+	journeys, total, err := s.journeyRepo.GetAll(filters, page, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]LearnerJourney, 0, len(journeys))
+	for i := range journeys {
+		version, err := s.versionService.LearnerVersion(userID, &journeys[i], false)
+		if err != nil {
+			return nil, 0, err
+		}
+		journey, err := s.summarizeJourney(userID, version)
+		if err != nil {
+			return nil, 0, err
+		}
+		result = append(result, *journey)
+	}
+
+	return result, total, nil
+}
+
+func (s *learnerService) GetPublishedScenario(userID, scenarioID string) (*LearnerScenario, error) {
+	// The scenario may since have been deleted from the draft and still be
+	// part of the learner's version
+	journeyID, err := s.scenarioRepo.GetJourneyID(scenarioID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("scenario not found")
+		}
+		return nil, err
+	}
+
+	// Scenarios of unpublished journeys are invisible to learners
+	journey, err := s.journeyRepo.GetByID(journeyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("scenario not found")
+		}
+		return nil, err
+	}
+	if journey.Status != "published" {
+		return nil, errors.New("scenario not found")
+	}
+
+	version, err := s.versionService.LearnerVersion(userID, journey, true)
+	if err != nil {
+		return nil, err
+	}
+	scenario, ok := version.Scenario(scenarioID)
+	if !ok {
+		return nil, errors.New("scenario not found")
+	}
+
+	wordIDs := make([]string, len(scenario.Words))
+	for i, word := range scenario.Words {
+		wordIDs[i] = word.ID
+	}
+
+	progressByWord, err := s.progressByWord(userID, wordIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var completed int64
+	words := make([]LearnerWord, len(scenario.Words))
+	for i, word := range scenario.Words {
+		lw := LearnerWord{
+			ID:           word.ID,
+			TargetText:   word.TargetText,
+			SourceText:   word.SourceText,
+			DisplayOrder: word.DisplayOrder,
+			ImageURL:     signURL(s.signer, word.ImageURL),
+			ImageSet:     media.ImageSetFor(word.ImageURL).Map(s.signer.Sign),
+			AudioURL:     signURL(s.signer, word.AudioURL),
+			MasteryLevel: "new",
+		}
+		if p, ok := progressByWord[word.ID]; ok {
+			lw.MasteryLevel = p.MasteryLevel
+			lw.ViewCount = p.ViewCount
+			lw.LastViewedAt = p.LastViewedAt
+			if p.MasteryLevel != "new" {
+				completed++
+			}
+		}
+		words[i] = lw
+	}
+
+	quizzes := make([]LearnerQuiz, len(scenario.Quizzes))
+	for i, quiz := range scenario.Quizzes {
+		quizzes[i] = LearnerQuiz{ID: quiz.ID, Title: quiz.Title, QuestionCount: len(quiz.Questions)}
+	}
+
+	wordCount := int64(len(words))
+	return &LearnerScenario{
+		LearnerScenarioSummary: LearnerScenarioSummary{
+			ID:                scenario.ID,
+			JourneyID:         journey.ID,
+			Title:             scenario.Title,
+			Description:       scenario.Description,
+			DisplayOrder:      scenario.DisplayOrder,
+			WordCount:         wordCount,
+			CompletionPercent: completionPercent(completed, wordCount),
+		},
+		Words:   words,
+		Quizzes: quizzes,
+	}, nil
+}
+
+// UpgradeJourney moves the learner to the live version of a journey without
+// waiting for them to finish the one they are on
+func (s *learnerService) UpgradeJourney(userID, journeyID string) (*LearnerJourney, error) {
+	version, err := s.versionService.UpgradeLearner(userID, journeyID)
+	if err != nil {
+		return nil, err
+	}
+	return s.summarizeJourney(userID, version)
+}
+
+// summarizeJourney annotates a version's scenarios with word counts and the
+// user's completion
+func (s *learnerService) summarizeJourney(userID string, version *PinnedVersion) (*LearnerJourney, error) {
+	progressByWord, err := s.progressByWord(userID, version.WordIDs())
+	if err != nil {
+		return nil, err
+	}
+
+	content := version.Content
+	var totalWords, totalCompleted int64
+	summaries := make([]LearnerScenarioSummary, len(content.Scenarios))
+	for i, scenario := range content.Scenarios {
+		var completed int64
+		for _, word := range scenario.Words {
+			if p, ok := progressByWord[word.ID]; ok && p.MasteryLevel != "new" {
+				completed++
+			}
+		}
+		wordCount := int64(len(scenario.Words))
+		summaries[i] = LearnerScenarioSummary{
+			ID:                scenario.ID,
+			JourneyID:         content.ID,
+			Title:             scenario.Title,
+			Description:       scenario.Description,
+			DisplayOrder:      scenario.DisplayOrder,
+			WordCount:         wordCount,
+			CompletionPercent: completionPercent(completed, wordCount),
+		}
+		totalWords += wordCount
+		totalCompleted += completed
+	}
+
+	return &LearnerJourney{
+		ID:                content.ID,
+		Title:             content.Title,
+		Description:       content.Description,
+		SourceLanguage:    content.SourceLanguage,
+		TargetLanguage:    content.TargetLanguage,
+		Version:           version.Version,
+		LiveVersion:       version.LiveVersion,
+		UpdateAvailable:   version.UpdateAvailable(),
+		ScenarioCount:     len(summaries),
+		WordCount:         totalWords,
+		CompletionPercent: completionPercent(totalCompleted, totalWords),
+		Scenarios:         summaries,
+	}, nil
+}
+
+func (s *learnerService) progressByWord(userID string, wordIDs []string) (map[string]models.LearnerProgress, error) {
+	progress, err := s.progressRepo.GetByUserAndWordIDs(userID, wordIDs)
+	if err != nil {
+		return nil, err
+	}
+	byWord := make(map[string]models.LearnerProgress, len(progress))
+	for _, p := range progress {
+		byWord[p.WordID] = p
+	}
+	return byWord, nil
+}
+
+// completionPercent returns completed/total as a percentage rounded to one decimal
+func completionPercent(completed, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(completed)/float64(total)*1000) / 10
+}
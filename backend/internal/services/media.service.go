@@ -0,0 +1,367 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/learng/backend/internal/media"
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/repository"
+	"github.com/learng/backend/internal/storage"
+	"gorm.io/gorm"
+)
+
+// MediaAssetView is an asset with the number of live words that use it
+type MediaAssetView struct {
+	models.MediaAsset
+	ImageSet   *media.ImageSet `json:"imageSet,omitempty"`
+	UsageCount int64           `json:"usageCount"`
+}
+
+type MediaService interface {
+	Save(userID string, data []byte, info *media.Info) (*models.MediaAsset, bool, error)
+	Remove(asset *models.MediaAsset) error
+	Open(key string) (io.ReadSeekCloser, *storage.ObjectInfo, error)
+	ListAssets(kind string) ([]MediaAssetView, error)
+	EnsureWordAssets() error
+	CollectGarbage(grace time.Duration, dryRun bool) (*MediaGCReport, error)
+	StartGCJob(interval, grace time.Duration)
+}
+
+type mediaService struct {
+	assetRepo        repository.MediaAssetRepository
+	versionRepo      repository.VersionRepository
+	store            storage.Storage
+	maxAudioDuration time.Duration
+	decoder          *media.FFmpeg
+}
+
+func NewMediaService(
+	assetRepo repository.MediaAssetRepository,
+	versionRepo repository.VersionRepository,
+	store storage.Storage,
+	maxAudioDuration time.Duration,
+	decoder *media.FFmpeg,
+) MediaService {
+	return &mediaService{
+		assetRepo:        assetRepo,
+		versionRepo:      versionRepo,
+		store:            store,
+		maxAudioDuration: maxAudioDuration,
+		decoder:          decoder,
+	}
+}
+
+// Save stores a validated upload as a new asset, or returns the asset already
+// holding the same content. It reports whether a new asset was created.
+// Images are stored as a set of variants under a prefix named after the
+// asset; audio is trimmed and normalized, then stored as a single object.
+// Content is deduplicated by the upload as received, before processing.
+func (s *mediaService) Save(userID string, data []byte, info *media.Info) (*models.MediaAsset, bool, error) {
+	hash := hashOf(data)
+	existing, err := s.reuse(hash)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	asset := &models.MediaAsset{
+		ID:         uuid.New().String(),
+		Hash:       hash,
+		Kind:       info.Kind,
+		MIMEType:   info.MIME,
+		UploadedBy: userID,
+		LastUsedAt: time.Now(),
+	}
+	if info.Kind == media.KindImage {
+		err = s.storeImage(asset, data, info)
+	} else {
+		err = s.storeAudio(asset, data, info)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := s.assetRepo.Create(asset); err != nil {
+		s.removeFiles(asset)
+		// A concurrent upload of the same content got there first
+		if existing, reuseErr := s.reuse(hash); reuseErr == nil {
+			return existing, false, nil
+		}
+		return nil, false, err
+	}
+	return asset, true, nil
+}
+
+// reuse returns the asset holding content with the given hash and marks it
+// used, or gorm.ErrRecordNotFound
+func (s *mediaService) reuse(hash string) (*models.MediaAsset, error) {
+	asset, err := s.assetRepo.GetByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.assetRepo.MarkUsed(asset.ID); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// Remove deletes the asset and its files
+func (s *mediaService) Remove(asset *models.MediaAsset) error {
+	if err := s.assetRepo.Delete(asset.ID); err != nil {
+		return err
+	}
+	return s.removeFiles(asset)
+}
+
+// Open streams a stored object, e.g. "images/<id>/full.jpg"
+func (s *mediaService) Open(key string) (io.ReadSeekCloser, *storage.ObjectInfo, error) {
+	return s.store.Get(key)
+}
+
+// ListAssets returns assets of the given kind, or all assets when kind is
+// empty, with their usage counts
+func (s *mediaService) ListAssets(kind string) ([]MediaAssetView, error) {
+	if kind != "" && kind != media.KindImage && kind != media.KindAudio {
+		return nil, errors.New("kind must be image or audio")
+	}
+
+	assets, err := s.assetRepo.ListWithUsage(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]MediaAssetView, 0, len(assets))
+	for _, asset := range assets {
+		view := MediaAssetView{MediaAsset: asset.MediaAsset, UsageCount: asset.UsageCount}
+		if len(asset.Variants) > 0 {
+			view.ImageSet = media.ImageSetFor(&asset.URL)
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// EnsureWordAssets registers the uploads that words used before assets were
+// tracked and links the words to them. Files that are missing or not valid
+// media are left unlinked.
+func (s *mediaService) EnsureWordAssets() error {
+	unlinked, err := s.assetRepo.GetUnlinked(s.store.URL(""))
+	if err != nil {
+		return err
+	}
+
+	for _, u := range unlinked {
+		asset, err := s.assetRepo.GetByURL(u.URL)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			asset, err = s.registerFile(u.URL, u.OwnerID)
+			if err != nil {
+				log.Printf("Skipped media %s: %v", u.URL, err)
+				continue
+			}
+			log.Printf("Registered media asset %s for %s", asset.ID, u.URL)
+		} else if err != nil {
+			return err
+		}
+		if err := s.assetRepo.LinkWords(asset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerFile creates an asset for an object already in storage, as it is.
+// An object with the same content as an existing asset is not registered,
+// since an asset's content is unique.
+func (s *mediaService) registerFile(url, ownerID string) (*models.MediaAsset, error) {
+	key, ok := storage.KeyFromURL(s.store, url)
+	if !ok {
+		return nil, errors.New("not a storage URL")
+	}
+	data, err := s.read(key)
+	if err != nil {
+		return nil, err
+	}
+	info, err := media.Detect(data)
+	if err != nil {
+		return nil, err
+	}
+
+	asset := &models.MediaAsset{
+		Hash:       hashOf(data),
+		Kind:       info.Kind,
+		MIMEType:   info.MIME,
+		URL:        url,
+		Size:       int64(len(data)),
+		Width:      info.Width,
+		Height:     info.Height,
+		UploadedBy: ownerID,
+		LastUsedAt: time.Now(),
+	}
+	if media.ImageSetFor(&url) != nil {
+		variants, err := s.readVariants(path.Dir(key) + "/")
+		if err != nil {
+			return nil, err
+		}
+		asset.Variants = variants
+		asset.Size = 0
+		for _, v := range variants {
+			asset.Size += v.Size
+		}
+	}
+	if info.Kind == media.KindAudio {
+		asset.Duration = &info.Duration
+	}
+	if existing, err := s.assetRepo.GetByHash(asset.Hash); err == nil {
+		return nil, fmt.Errorf("same content as asset %s", existing.ID)
+	}
+	if err := s.assetRepo.Create(asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// readVariants describes the variant objects of an image set
+func (s *mediaService) readVariants(prefix string) ([]models.MediaVariant, error) {
+	objects, err := s.store.List(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []models.MediaVariant
+	for _, obj := range objects {
+		name := path.Base(obj.Key)
+		if obj.Key != prefix+name {
+			continue
+		}
+		data, err := s.read(obj.Key)
+		if err != nil {
+			return nil, err
+		}
+		info, err := media.Detect(data)
+		if err != nil {
+			continue
+		}
+		variants = append(variants, models.MediaVariant{
+			Name:     strings.TrimSuffix(name, path.Ext(name)),
+			MIMEType: info.MIME,
+			URL:      s.store.URL(obj.Key),
+			Width:    info.Width,
+			Height:   info.Height,
+			Size:     int64(len(data)),
+		})
+	}
+	return variants, nil
+}
+
+func (s *mediaService) storeImage(asset *models.MediaAsset, data []byte, info *media.Info) error {
+	variants, err := media.ProcessImage(data, info)
+	if err != nil {
+		return err
+	}
+
+	prefix := "images/" + asset.ID + "/"
+	for _, v := range variants {
+		key := prefix + v.Filename()
+		if err := s.store.Put(key, bytes.NewReader(v.Data), int64(len(v.Data)), v.MIME); err != nil {
+			s.removeFiles(asset)
+			return err
+		}
+
+		variant := models.MediaVariant{
+			Name:     v.Name,
+			MIMEType: v.MIME,
+			URL:      s.store.URL(key),
+			Width:    v.Width,
+			Height:   v.Height,
+			Size:     int64(len(v.Data)),
+		}
+		if v.Name == media.VariantFull && v.MIME == info.MIME {
+			asset.URL = variant.URL
+			asset.Width = v.Width
+			asset.Height = v.Height
+		}
+		asset.Size += variant.Size
+		asset.Variants = append(asset.Variants, variant)
+	}
+	return nil
+}
+
+func (s *mediaService) storeAudio(asset *models.MediaAsset, data []byte, info *media.Info) error {
+	data, info, err := media.ProcessAudio(data, info, s.maxAudioDuration, s.decoder)
+	if err != nil {
+		return err
+	}
+
+	key := "audio/" + asset.ID + info.Ext
+	if err := s.store.Put(key, bytes.NewReader(data), int64(len(data)), info.MIME); err != nil {
+		return err
+	}
+
+	asset.URL = s.store.URL(key)
+	asset.MIMEType = info.MIME
+	asset.Size = int64(len(data))
+	asset.Duration = &info.Duration
+	return nil
+}
+
+// removeFiles deletes every object the asset stores: an image's variants, or
+// the single file otherwise
+func (s *mediaService) removeFiles(asset *models.MediaAsset) error {
+	for _, url := range assetURLs(asset) {
+		key, ok := storage.KeyFromURL(s.store, url)
+		if !ok {
+			continue
+		}
+		if err := s.store.Delete(key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// read loads a whole object
+func (s *mediaService) read(key string) ([]byte, error) {
+	rc, _, err := s.store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// signURL signs a stored media URL for clients that fetch it without an
+// access token, such as image and audio elements
+func signURL(signer *storage.URLSigner, url *string) *string {
+	if url == nil || *url == "" {
+		return url
+	}
+	signed := signer.Sign(*url)
+	return &signed
+}
+
+// unsignedURL strips a signature from a media URL sent back by a client
+func unsignedURL(url *string) *string {
+	if url == nil {
+		return nil
+	}
+	plain := storage.Unsigned(*url)
+	return &plain
+}
+
+// hashOf is the content hash assets are deduplicated by
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/learng/backend/internal/media"
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/storage"
+)
+
+// mediaPrefixes are the storage key prefixes that garbage collection scans
+var mediaPrefixes = []string{"images/", "audio/"}
+
+// MediaGCReport lists what a garbage collection run deleted, or would delete
+// on a dry run
+type MediaGCReport struct {
+	DryRun         bool          `json:"dryRun"`
+	Cutoff         time.Time     `json:"cutoff"` // Media used since this was kept
+	Assets         int           `json:"assets"`
+	Files          int           `json:"files"`
+	ReclaimedBytes int64         `json:"reclaimedBytes"`
+	Items          []MediaGCItem `json:"items"`
+}
+
+// MediaGCItem is an unreferenced asset, or a file that belongs to no asset
+type MediaGCItem struct {
+	Type    string `json:"type"` // 'asset' | 'file'
+	AssetID string `json:"assetId,omitempty"`
+	URL     string `json:"url"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// CollectGarbage deletes media unused for grace that nothing references: first
+// assets no word or published version uses, then objects under the images
+// and audio prefixes that belong to no asset and are not referenced either.
+// An asset counts as used when created and whenever the same content is
+// uploaded again. Words in the trash still count as references, since they
+// can be restored.
+// With dryRun set nothing is deleted and the report lists what would be.
+func (s *mediaService) CollectGarbage(grace time.Duration, dryRun bool) (*MediaGCReport, error) {
+	report := &MediaGCReport{
+		DryRun: dryRun,
+		Cutoff: time.Now().Add(-grace),
+		Items:  []MediaGCItem{},
+	}
+
+	referenced, err := s.referencedURLs()
+	if err != nil {
+		return nil, err
+	}
+
+	assets, err := s.assetRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// Files that belong to an asset are never collected as stray files, even
+	// when their asset is collected: they go with it
+	owned := make(map[string]bool)
+	for i := range assets {
+		asset := &assets[i]
+		urls := assetURLs(asset)
+		inUse := false
+		for _, url := range urls {
+			owned[url] = true
+			inUse = inUse || referenced[url]
+		}
+
+		lastUsed := asset.CreatedAt
+		if asset.LastUsedAt.After(lastUsed) {
+			lastUsed = asset.LastUsedAt
+		}
+		if inUse || !lastUsed.Before(report.Cutoff) {
+			continue
+		}
+		item := MediaGCItem{Type: "asset", AssetID: asset.ID, URL: asset.URL}
+		for _, url := range urls {
+			if key, ok := storage.KeyFromURL(s.store, url); ok {
+				if info, err := s.store.Stat(key); err == nil {
+					item.Bytes += info.Size
+				}
+			}
+		}
+		if !dryRun {
+			if err := s.Remove(asset); err != nil {
+				return nil, err
+			}
+		}
+		report.Assets++
+		report.ReclaimedBytes += item.Bytes
+		report.Items = append(report.Items, item)
+	}
+
+	for _, prefix := range mediaPrefixes {
+		objects, err := s.store.List(prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			url := s.store.URL(obj.Key)
+			if owned[url] || referenced[url] || !obj.ModTime.Before(report.Cutoff) {
+				continue
+			}
+			if !dryRun {
+				if err := s.store.Delete(obj.Key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+					return nil, err
+				}
+			}
+			report.Files++
+			report.ReclaimedBytes += obj.Size
+			report.Items = append(report.Items, MediaGCItem{Type: "file", URL: url, Bytes: obj.Size})
+		}
+	}
+
+	return report, nil
+}
+
+// StartGCJob runs CollectGarbage now and then on every interval tick in the
+// background. A non-positive interval disables the job.
+func (s *mediaService) StartGCJob(interval, grace time.Duration) {
+	if interval <= 0 {
+		log.Println("Media garbage collection disabled")
+		return
+	}
+
+	collect := func() {
+		report, err := s.CollectGarbage(grace, false)
+		if err != nil {
+			log.Printf("Media garbage collection failed: %v", err)
+			return
+		}
+		if report.Assets > 0 || report.Files > 0 {
+			log.Printf("Media garbage collection removed %d assets and %d files, reclaiming %d bytes",
+				report.Assets, report.Files, report.ReclaimedBytes)
+		}
+	}
+
+	go func() {
+		collect()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			collect()
+		}
+	}()
+}
+
+// referencedURLs collects the media URLs used by words, including deleted
+// ones, and by every journey version. A URL in an image set references the
+// whole set.
+func (s *mediaService) referencedURLs() (map[string]bool, error) {
+	urls, err := s.assetRepo.GetWordURLs()
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versionRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range versions {
+		content, err := decodeSnapshot(&versions[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, scenario := range content.Scenarios {
+			for _, word := range scenario.Words {
+				for _, url := range []*string{word.ImageURL, word.AudioURL} {
+					if url != nil && *url != "" {
+						urls = append(urls, *url)
+					}
+				}
+			}
+		}
+	}
+
+	referenced := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		referenced[url] = true
+		if set := media.ImageSetFor(&url); set != nil {
+			for _, source := range []media.ImageSource{set.Thumbnail, set.Card, set.Full} {
+				referenced[source.WebP] = true
+				referenced[source.Original] = true
+			}
+		}
+	}
+	return referenced, nil
+}
+
+// assetURLs lists the URLs of every file an asset stores
+func assetURLs(asset *models.MediaAsset) []string {
+	urls := []string{asset.URL}
+	for _, v := range asset.Variants {
+		if v.URL != asset.URL {
+			urls = append(urls, v.URL)
+		}
+	}
+	return urls
+}
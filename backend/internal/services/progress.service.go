@@ -0,0 +1,288 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/learng/backend/internal/media"
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/repository"
+	"github.com/learng/backend/internal/storage"
+	"gorm.io/gorm"
+)
+
+// Mastery levels a learner moves through for each word
+const (
+	MasteryNew      = "new"
+	MasteryLearning = "learning"
+	MasteryReview   = "review"
+	MasteryMastered = "mastered"
+)
+
+// View thresholds for advancing mastery. A word moves to 'review' once it has
+// been seen reviewViewThreshold times, and to 'mastered' once it has been seen
+// masteredViewThreshold times and the learner reported knowing it.
+const (
+	reviewViewThreshold   = 3
+	masteredViewThreshold = 5
+)
+
+// RecordViewRequest contains the data sent when a learner views a word card
+type RecordViewRequest struct {
+	WordID string `json:"wordId"`
+	// Known is the learner's self-assessment; nil when the card was only viewed
+	Known *bool `json:"known"`
+}
+
+// DueReviews is the learner's review queue for today
+type DueReviews struct {
+	Reviews       []DueReview `json:"reviews"`
+	DailyCap      int         `json:"dailyCap"`
+	ReviewedToday int64       `json:"reviewedToday"`
+	Remaining     int         `json:"remaining"`
+}
+
+// DueReview is a word due for review together with its schedule
+type DueReview struct {
+	WordID       string          `json:"wordId"`
+	ScenarioID   string          `json:"scenarioId"`
+	JourneyID    string          `json:"journeyId"`
+	TargetText   string          `json:"targetText"`
+	SourceText   string          `json:"sourceText"`
+	ImageURL     *string         `json:"imageUrl"`
+	ImageSet     *media.ImageSet `json:"imageSet,omitempty"`
+	AudioURL     *string         `json:"audioUrl"`
+	MasteryLevel string          `json:"masteryLevel"`
+	EaseFactor   float64         `json:"easeFactor"`
+	IntervalDays int             `json:"intervalDays"`
+	LapseCount   int             `json:"lapseCount"`
+	DueAt        *time.Time      `json:"dueAt"`
+}
+
+type ProgressService interface {
+	RecordView(userID string, req RecordViewRequest) (*models.LearnerProgress, error)
+	RecordQuizResults(userID string, results map[string]bool) error
+	GetDueReviews(userID string, limit int) (*DueReviews, error)
+}
+
+type progressService struct {
+	progressRepo   repository.ProgressRepository
+	wordRepo       repository.WordRepository
+	journeyRepo    repository.JourneyRepository
+	versionService VersionService
+	dailyReviewCap int
+	signer         *storage.URLSigner
+}
+
+func NewProgressService(
+	progressRepo repository.ProgressRepository,
+	wordRepo repository.WordRepository,
+	journeyRepo repository.JourneyRepository,
+	versionService VersionService,
+	dailyReviewCap int,
+	signer *storage.URLSigner,
+) ProgressService {
+	return &progressService{
+		progressRepo:   progressRepo,
+		wordRepo:       wordRepo,
+		journeyRepo:    journeyRepo,
+		versionService: versionService,
+		dailyReviewCap: dailyReviewCap,
+		signer:         signer,
+	}
+}
+
+func (s *progressService) RecordView(userID string, req RecordViewRequest) (*models.LearnerProgress, error) {
+	if req.WordID == "" {
+		return nil, errors.New("word ID is required")
+	}
+
+	if err := s.ensurePublishedWord(userID, req.WordID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return s.progressRepo.Update(userID, req.WordID, func(progress *models.LearnerProgress) {
+		progress.ViewCount++
+		progress.LastViewedAt = &now
+		progress.MasteryLevel = nextMasteryLevel(progress.MasteryLevel, progress.ViewCount, req.Known)
+
+		if req.Known != nil {
+			quality := qualityForgot
+			if *req.Known {
+				quality = qualityRecalled
+			}
+			scheduleReview(progress, quality, now)
+		} else if progress.DueAt == nil {
+			// A first sighting schedules the first review for tomorrow
+			due := now.AddDate(0, 0, 1)
+			progress.DueAt = &due
+		}
+	})
+}
+
+// RecordQuizResults feeds graded quiz answers, keyed by word ID, into the
+// learner's mastery levels and review schedule
+func (s *progressService) RecordQuizResults(userID string, results map[string]bool) error {
+	now := time.Now()
+	for wordID, correct := range results {
+		quality := qualityForgot
+		if correct {
+			quality = qualityRecalled
+		}
+		_, err := s.progressRepo.Update(userID, wordID, func(progress *models.LearnerProgress) {
+			progress.MasteryLevel = nextMasteryLevel(progress.MasteryLevel, progress.ViewCount, &correct)
+			scheduleReview(progress, quality, now)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDueReviews returns the words due for review now, capped so the learner
+// reviews at most dailyReviewCap words per day. Cards show the words as they
+// are in the learner's version of each journey.
+func (s *progressService) GetDueReviews(userID string, limit int) (*DueReviews, error) {
+	now := time.Now()
+
+	reviewedToday, err := s.progressRepo.CountReviewedSince(userID, startOfDay(now))
+	if err != nil {
+		return nil, err
+	}
+
+	remaining := s.dailyReviewCap - int(reviewedToday)
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	result := &DueReviews{
+		Reviews:       []DueReview{},
+		DailyCap:      s.dailyReviewCap,
+		ReviewedToday: reviewedToday,
+		Remaining:     remaining,
+	}
+	if remaining == 0 {
+		return result, nil
+	}
+
+	if limit < 1 || limit > remaining {
+		limit = remaining
+	}
+
+	// Rows are fetched a page at a time until the limit is filled, since
+	// words outside the learner's versions are only skipped once loaded
+	versions := make(map[string]*PinnedVersion)
+	for offset := 0; len(result.Reviews) < limit; offset += limit {
+		due, err := s.progressRepo.GetDue(userID, now, offset, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range due {
+			if len(result.Reviews) == limit {
+				break
+			}
+			journeyID := p.Word.Scenario.JourneyID
+			version, ok := versions[journeyID]
+			if !ok {
+				journey, err := s.journeyRepo.GetByID(journeyID)
+				if err != nil {
+					return nil, err
+				}
+				if version, err = s.versionService.LearnerVersion(userID, journey, false); err != nil {
+					return nil, err
+				}
+				versions[journeyID] = version
+			}
+
+			// Words dropped from the learner's version are not reviewed
+			word, scenario, ok := version.Word(p.WordID)
+			if !ok {
+				continue
+			}
+			result.Reviews = append(result.Reviews, DueReview{
+				WordID:       p.WordID,
+				ScenarioID:   scenario.ID,
+				JourneyID:    journeyID,
+				TargetText:   word.TargetText,
+				SourceText:   word.SourceText,
+				ImageURL:     signURL(s.signer, word.ImageURL),
+				ImageSet:     media.ImageSetFor(word.ImageURL).Map(s.signer.Sign),
+				AudioURL:     signURL(s.signer, word.AudioURL),
+				MasteryLevel: p.MasteryLevel,
+				EaseFactor:   p.EaseFactor,
+				IntervalDays: p.IntervalDays,
+				LapseCount:   p.LapseCount,
+				DueAt:        p.DueAt,
+			})
+		}
+
+		if len(due) < limit {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// ensurePublishedWord verifies the word belongs to a published journey and
+// is part of the learner's version of it
+func (s *progressService) ensurePublishedWord(userID, wordID string) error {
+	journeyID, err := s.wordRepo.GetJourneyID(wordID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("word not found")
+		}
+		return err
+	}
+
+	journey, err := s.journeyRepo.GetByID(journeyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("word not found")
+		}
+		return err
+	}
+	if journey.Status != "published" {
+		return errors.New("word not found")
+	}
+
+	version, err := s.versionService.LearnerVersion(userID, journey, true)
+	if err != nil {
+		return err
+	}
+	if _, _, ok := version.Word(wordID); !ok {
+		return errors.New("word not found")
+	}
+
+	return nil
+}
+
+// nextMasteryLevel applies the mastery transition rules:
+//   - any view moves a 'new' word to 'learning'
+//   - reporting the word as not known drops it back to 'learning'
+//   - 'learning' advances to 'review' after reviewViewThreshold views
+//   - 'review' advances to 'mastered' after masteredViewThreshold views when known
+func nextMasteryLevel(current string, viewCount int, known *bool) string {
+	if known != nil && !*known {
+		return MasteryLearning
+	}
+
+	if current == MasteryNew || current == "" {
+		current = MasteryLearning
+	}
+
+	switch current {
+	case MasteryLearning:
+		if viewCount >= reviewViewThreshold {
+			return MasteryReview
+		}
+	case MasteryReview:
+		if viewCount >= masteredViewThreshold && known != nil && *known {
+			return MasteryMastered
+		}
+	}
+	return current
+}
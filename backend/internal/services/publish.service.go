@@ -0,0 +1,371 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/repository"
+	"github.com/learng/backend/internal/utils"
+	"gorm.io/gorm"
+)
+
+// Journey statuses
+const (
+	JourneyDraft     = "draft"
+	JourneyScheduled = "scheduled"
+	JourneyPublished = "published"
+	JourneyArchived  = "archived"
+)
+
+// journeyTransitions is the publishing state machine: the statuses each
+// status may move to
+var journeyTransitions = map[string][]string{
+	JourneyDraft:     {JourneyScheduled, JourneyPublished, JourneyArchived},
+	JourneyScheduled: {JourneyDraft, JourneyPublished, JourneyArchived},
+	JourneyPublished: {JourneyPublished, JourneyDraft, JourneyArchived}, // Republishing releases a new version
+	JourneyArchived:  {JourneyDraft},
+}
+
+// PublishRequest publishes a journey now, or at PublishAt when it is in the future
+type PublishRequest struct {
+	PublishAt *time.Time `json:"publishAt"`
+}
+
+// PublishReport is the outcome of checking a journey is ready to publish.
+// Errors block publishing; warnings do not.
+type PublishReport struct {
+	CanPublish bool           `json:"canPublish"`
+	Errors     []PublishIssue `json:"errors"`
+	Warnings   []PublishIssue `json:"warnings"`
+	Scenarios  int            `json:"scenarios"`
+	Words      int            `json:"words"`
+}
+
+type PublishIssue struct {
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	ScenarioID string `json:"scenarioId,omitempty"`
+	WordID     string `json:"wordId,omitempty"`
+}
+
+// PublishResult is the journey after a publish or schedule, with the report
+// it was validated against
+type PublishResult struct {
+	Journey *models.Journey `json:"journey"`
+	Report  *PublishReport  `json:"report"`
+}
+
+// ErrPublishBlocked is returned when the validation report has errors
+var ErrPublishBlocked = errors.New("journey has validation errors and cannot be published")
+
+type PublishService interface {
+	ValidateJourney(id string) (*PublishReport, error)
+	Publish(id, userID string, req PublishRequest) (*PublishResult, error)
+	Unpublish(id string) (*models.Journey, error)
+	Archive(id string) (*models.Journey, error)
+	PublishScheduled(now time.Time) (int, error)
+	StartPublishJob(interval time.Duration)
+}
+
+type publishService struct {
+	journeyRepo  repository.JourneyRepository
+	versionRepo  repository.VersionRepository
+	scenarioRepo repository.ScenarioRepository
+	wordRepo     repository.WordRepository
+	quizRepo     repository.QuizRepository
+}
+
+func NewPublishService(
+	journeyRepo repository.JourneyRepository,
+	versionRepo repository.VersionRepository,
+	scenarioRepo repository.ScenarioRepository,
+	wordRepo repository.WordRepository,
+	quizRepo repository.QuizRepository,
+) PublishService {
+	return &publishService{
+		journeyRepo:  journeyRepo,
+		versionRepo:  versionRepo,
+		scenarioRepo: scenarioRepo,
+		wordRepo:     wordRepo,
+		quizRepo:     quizRepo,
+	}
+}
+
+// ValidateJourney reports whether a journey is ready to publish without
+// changing it
+func (s *publishService) ValidateJourney(id string) (*PublishReport, error) {
+	journey, err := s.getJourney(id)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := loadJourneyTree(s.scenarioRepo, s.wordRepo, s.quizRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.validate(journey, tree), nil
+}
+
+// Publish validates the journey and publishes it as a new version, or
+// schedules it when PublishAt is in the future. On ErrPublishBlocked the result
+// still carries the report so callers can show what needs fixing.
+func (s *publishService) Publish(id, userID string, req PublishRequest) (*PublishResult, error) {
+	journey, err := s.getJourney(id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	target := JourneyPublished
+	if req.PublishAt != nil && req.PublishAt.After(now) {
+		target = JourneyScheduled
+	}
+	if err := checkTransition(journey.Status, target); err != nil {
+		return nil, err
+	}
+
+	tree, err := loadJourneyTree(s.scenarioRepo, s.wordRepo, s.quizRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	report := s.validate(journey, tree)
+	if !report.CanPublish {
+		return &PublishResult{Journey: journey, Report: report}, ErrPublishBlocked
+	}
+
+	if target == JourneyScheduled {
+		err = s.transition(journey, map[string]interface{}{
+			"status":     target,
+			"publish_at": req.PublishAt.UTC(),
+		})
+	} else {
+		err = s.release(journey, tree, userID, now)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	journey, err = s.getJourney(id)
+	if err != nil {
+		return nil, err
+	}
+	return &PublishResult{Journey: journey, Report: report}, nil
+}
+
+// Unpublish returns a published, scheduled or archived journey to draft
+func (s *publishService) Unpublish(id string) (*models.Journey, error) {
+	return s.moveTo(id, JourneyDraft, map[string]interface{}{
+		"status":     JourneyDraft,
+		"publish_at": nil,
+	})
+}
+
+// Archive hides a journey from learners without deleting it
+func (s *publishService) Archive(id string) (*models.Journey, error) {
+	return s.moveTo(id, JourneyArchived, map[string]interface{}{
+		"status":     JourneyArchived,
+		"publish_at": nil,
+	})
+}
+
+// PublishScheduled publishes every scheduled journey whose release time has
+// passed. A journey that no longer validates goes back to draft instead.
+func (s *publishService) PublishScheduled(now time.Time) (int, error) {
+	due, err := s.journeyRepo.GetScheduledDue(now)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for i := range due {
+		journey := &due[i]
+
+		tree, err := loadJourneyTree(s.scenarioRepo, s.wordRepo, s.quizRepo, journey.ID)
+		if err != nil {
+			return published, err
+		}
+
+		report := s.validate(journey, tree)
+		if !report.CanPublish {
+			log.Printf("Scheduled publish of journey %s blocked by %d validation errors; returned to draft",
+				journey.ID, len(report.Errors))
+			if _, err := s.journeyRepo.UpdateStatus(journey.ID, JourneyScheduled, map[string]interface{}{
+				"status":     JourneyDraft,
+				"publish_at": nil,
+			}); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		version, err := newJourneyVersion(journey, tree, journey.CreatedBy)
+		if err != nil {
+			return published, err
+		}
+		ok, err := s.versionRepo.Publish(journey.ID, JourneyScheduled, map[string]interface{}{
+			"status":       JourneyPublished,
+			"publish_at":   nil,
+			"published_at": now,
+		}, version)
+		if err != nil {
+			return published, err
+		}
+		if ok {
+			published++
+		}
+	}
+	return published, nil
+}
+
+// StartPublishJob checks for due scheduled journeys on every interval tick in
+// the background. A non-positive interval disables the job.
+func (s *publishService) StartPublishJob(interval time.Duration) {
+	if interval <= 0 {
+		log.Println("Scheduled publishing disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			n, err := s.PublishScheduled(time.Now().UTC())
+			if err != nil {
+				log.Printf("Scheduled publish failed: %v", err)
+			}
+			if n > 0 {
+				log.Printf("Published %d scheduled journeys", n)
+			}
+		}
+	}()
+}
+
+func (s *publishService) moveTo(id, target string, updates map[string]interface{}) (*models.Journey, error) {
+	journey, err := s.getJourney(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTransition(journey.Status, target); err != nil {
+		return nil, err
+	}
+	if err := s.transition(journey, updates); err != nil {
+		return nil, err
+	}
+	return s.getJourney(id)
+}
+
+// release publishes the journey now, snapshotting tree as its new live version
+func (s *publishService) release(journey *models.Journey, tree *journeyTree, userID string, now time.Time) error {
+	version, err := newJourneyVersion(journey, tree, userID)
+	if err != nil {
+		return err
+	}
+	ok, err := s.versionRepo.Publish(journey.ID, journey.Status, map[string]interface{}{
+		"status":       JourneyPublished,
+		"publish_at":   nil,
+		"published_at": now,
+	}, version)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("journey status changed concurrently; reload and try again")
+	}
+	return nil
+}
+
+// transition applies a status change, failing if the status moved since the
+// journey was loaded
+func (s *publishService) transition(journey *models.Journey, updates map[string]interface{}) error {
+	ok, err := s.journeyRepo.UpdateStatus(journey.ID, journey.Status, updates)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("journey status changed concurrently; reload and try again")
+	}
+	return nil
+}
+
+func (s *publishService) getJourney(id string) (*models.Journey, error) {
+	journey, err := s.journeyRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("journey not found")
+		}
+		return nil, err
+	}
+	return journey, nil
+}
+
+// validate builds the publish report for a journey and its tree
+func (s *publishService) validate(journey *models.Journey, tree *journeyTree) *PublishReport {
+	scenarios, words, quizzes := tree.Scenarios, tree.Words, tree.Quizzes
+
+	report := &PublishReport{
+		Errors:    []PublishIssue{},
+		Warnings:  []PublishIssue{},
+		Scenarios: len(scenarios),
+		Words:     len(words),
+	}
+	addError := func(issue PublishIssue) { report.Errors = append(report.Errors, issue) }
+	addWarning := func(issue PublishIssue) { report.Warnings = append(report.Warnings, issue) }
+
+	if !utils.ValidateLanguageCode(journey.SourceLanguage) {
+		addError(PublishIssue{Code: "invalid_source_language", Message: fmt.Sprintf("source language %q is not supported", journey.SourceLanguage)})
+	}
+	if !utils.ValidateLanguageCode(journey.TargetLanguage) {
+		addError(PublishIssue{Code: "invalid_target_language", Message: fmt.Sprintf("target language %q is not supported", journey.TargetLanguage)})
+	}
+	if journey.SourceLanguage == journey.TargetLanguage {
+		addError(PublishIssue{Code: "same_languages", Message: "source and target languages must differ"})
+	}
+	if len(scenarios) == 0 {
+		addError(PublishIssue{Code: "no_scenarios", Message: "journey has no scenarios"})
+	}
+
+	wordsByScenario := make(map[string]int, len(scenarios))
+	for _, word := range words {
+		wordsByScenario[word.ScenarioID]++
+		if word.AudioURL == nil || *word.AudioURL == "" {
+			addError(PublishIssue{Code: "missing_audio", Message: fmt.Sprintf("word %q has no audio", word.TargetText), ScenarioID: word.ScenarioID, WordID: word.ID})
+		}
+		if word.ImageURL == nil || *word.ImageURL == "" {
+			addWarning(PublishIssue{Code: "missing_image", Message: fmt.Sprintf("word %q has no image", word.TargetText), ScenarioID: word.ScenarioID, WordID: word.ID})
+		}
+		if word.SourceText == "" {
+			addWarning(PublishIssue{Code: "missing_translation", Message: fmt.Sprintf("word %q has no translation", word.TargetText), ScenarioID: word.ScenarioID, WordID: word.ID})
+		}
+	}
+
+	quizzesByScenario := make(map[string]int, len(scenarios))
+	for _, quiz := range quizzes {
+		if len(quiz.Questions) > 0 {
+			quizzesByScenario[quiz.ScenarioID]++
+		}
+	}
+
+	for _, scenario := range scenarios {
+		if wordsByScenario[scenario.ID] == 0 {
+			addError(PublishIssue{Code: "empty_scenario", Message: fmt.Sprintf("scenario %q has no words", scenario.Title), ScenarioID: scenario.ID})
+		}
+		if quizzesByScenario[scenario.ID] == 0 {
+			addError(PublishIssue{Code: "missing_quiz", Message: fmt.Sprintf("scenario %q has no quiz", scenario.Title), ScenarioID: scenario.ID})
+		}
+	}
+
+	report.CanPublish = len(report.Errors) == 0
+	return report
+}
+
+// checkTransition enforces the publishing state machine
+func checkTransition(from, to string) error {
+	for _, allowed := range journeyTransitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot move journey from %s to %s", from, to)
+}
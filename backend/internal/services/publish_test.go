@@ -0,0 +1,38 @@
+package services
+
+import "testing"
+
+func TestCheckTransition(t *testing.T) {
+	tests := []struct {
+		from, to string
+		allowed  bool
+	}{
+		{from: JourneyDraft, to: JourneyPublished, allowed: true},
+		{from: JourneyDraft, to: JourneyScheduled, allowed: true},
+		{from: JourneyDraft, to: JourneyArchived, allowed: true},
+		{from: JourneyDraft, to: JourneyDraft},
+		{from: JourneyScheduled, to: JourneyPublished, allowed: true},
+		{from: JourneyScheduled, to: JourneyDraft, allowed: true},
+		{from: JourneyScheduled, to: JourneyScheduled},
+		{from: JourneyPublished, to: JourneyPublished, allowed: true},
+		{from: JourneyPublished, to: JourneyDraft, allowed: true},
+		{from: JourneyPublished, to: JourneyArchived, allowed: true},
+		{from: JourneyPublished, to: JourneyScheduled},
+		{from: JourneyArchived, to: JourneyDraft, allowed: true},
+		{from: JourneyArchived, to: JourneyPublished},
+		{from: JourneyArchived, to: JourneyScheduled},
+		{from: "deleted", to: JourneyDraft},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+" to "+tt.to, func(t *testing.T) {
+			err := checkTransition(tt.from, tt.to)
+			if tt.allowed && err != nil {
+				t.Errorf("checkTransition() = %v, want nil", err)
+			}
+			if !tt.allowed && err == nil {
+				t.Error("checkTransition() = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,515 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+
+	"github.com/learng/backend/internal/media"
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/repository"
+	"github.com/learng/backend/internal/storage"
+	"gorm.io/gorm"
+)
+
+// Quiz question types
+const (
+	QuestionTypeMultipleChoice = "multiple_choice"
+	QuestionTypeAudioMatch     = "audio_match"
+	QuestionTypeImageMatch     = "image_match"
+)
+
+// maxQuizOptions is the number of choices offered per question, including the answer
+const maxQuizOptions = 4
+
+// GenerateQuizRequest contains the options for building a quiz from a scenario
+type GenerateQuizRequest struct {
+	Title         string   `json:"title"`
+	QuestionCount int      `json:"questionCount"` // 0 means one question per word
+	QuestionTypes []string `json:"questionTypes"` // empty means all types
+	PassThreshold float64  `json:"passThreshold"`
+}
+
+// QuizView is a quiz as presented to a learner, without correct answers
+type QuizView struct {
+	ID            string             `json:"id"`
+	ScenarioID    string             `json:"scenarioId"`
+	Title         string             `json:"title"`
+	PassThreshold float64            `json:"passThreshold"`
+	Questions     []QuizQuestionView `json:"questions"`
+}
+
+// QuizQuestionView is a question with only the media its type needs
+type QuizQuestionView struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	QuestionText string          `json:"questionText"`
+	ImageURL     *string         `json:"imageUrl,omitempty"`
+	ImageSet     *media.ImageSet `json:"imageSet,omitempty"`
+	AudioURL     *string         `json:"audioUrl,omitempty"`
+	Options      []string        `json:"options"`
+}
+
+// SubmitQuizRequest contains a learner's answers to a quiz
+type SubmitQuizRequest struct {
+	Answers []QuizAnswer `json:"answers"`
+}
+
+// QuizAnswer is the learner's answer to a single question
+type QuizAnswer struct {
+	QuestionID string `json:"questionId"`
+	Answer     string `json:"answer"`
+}
+
+// QuizResult is the graded outcome of a quiz submission
+type QuizResult struct {
+	AttemptID      string             `json:"attemptId"`
+	Score          float64            `json:"score"`
+	TotalQuestions int                `json:"totalQuestions"`
+	CorrectAnswers int                `json:"correctAnswers"`
+	PassThreshold  float64            `json:"passThreshold"`
+	Passed         bool               `json:"passed"`
+	Feedback       []QuestionFeedback `json:"feedback"`
+	MissedWords    []MissedWord       `json:"missedWords"`
+}
+
+// QuestionFeedback tells the learner whether a question was answered correctly
+type QuestionFeedback struct {
+	QuestionID    string `json:"questionId"`
+	Answer        string `json:"answer"`
+	Correct       bool   `json:"correct"`
+	CorrectAnswer string `json:"correctAnswer"`
+}
+
+// MissedWord is a word the learner answered incorrectly and should review
+type MissedWord struct {
+	ID         string `json:"id"`
+	TargetText string `json:"targetText"`
+	SourceText string `json:"sourceText"`
+}
+
+// attemptAnswer is the per-question record stored in QuizAttempt.Answers
+type attemptAnswer struct {
+	QuestionID string `json:"question_id"`
+	Answer     string `json:"answer"`
+	IsCorrect  bool   `json:"is_correct"`
+}
+
+type QuizService interface {
+	GenerateQuiz(scenarioID string, req GenerateQuizRequest) (*models.Quiz, error)
+	GetQuiz(id, userID string, publishedOnly bool) (*QuizView, error)
+	SubmitQuiz(userID, quizID string, req SubmitQuizRequest, publishedOnly bool) (*QuizResult, error)
+}
+
+type quizService struct {
+	quizRepo        repository.QuizRepository
+	scenarioRepo    repository.ScenarioRepository
+	wordRepo        repository.WordRepository
+	journeyRepo     repository.JourneyRepository
+	versionService  VersionService
+	progressService ProgressService
+	signer          *storage.URLSigner
+}
+
+func NewQuizService(
+	quizRepo repository.QuizRepository,
+	scenarioRepo repository.ScenarioRepository,
+	wordRepo repository.WordRepository,
+	journeyRepo repository.JourneyRepository,
+	versionService VersionService,
+	progressService ProgressService,
+	signer *storage.URLSigner,
+) QuizService {
+	return &quizService{
+		quizRepo:        quizRepo,
+		scenarioRepo:    scenarioRepo,
+		wordRepo:        wordRepo,
+		journeyRepo:     journeyRepo,
+		versionService:  versionService,
+		progressService: progressService,
+		signer:          signer,
+	}
+}
+
+func (s *quizService) GenerateQuiz(scenarioID string, req GenerateQuizRequest) (*models.Quiz, error) {
+	scenario, err := s.scenarioRepo.GetByIDWithWords(scenarioID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("scenario not found")
+		}
+		return nil, err
+	}
+	if len(scenario.Words) == 0 {
+		return nil, errors.New("scenario has no words")
+	}
+
+	types := req.QuestionTypes
+	if len(types) == 0 {
+		types = []string{QuestionTypeMultipleChoice, QuestionTypeAudioMatch, QuestionTypeImageMatch}
+	}
+	for _, t := range types {
+		if t != QuestionTypeMultipleChoice && t != QuestionTypeAudioMatch && t != QuestionTypeImageMatch {
+			return nil, fmt.Errorf("invalid question type: %s", t)
+		}
+	}
+
+	if req.QuestionCount < 0 {
+		return nil, errors.New("question count must not be negative")
+	}
+	count := req.QuestionCount
+	if count == 0 || count > len(scenario.Words) {
+		count = len(scenario.Words)
+	}
+
+	if req.PassThreshold < 0 || req.PassThreshold > 100 {
+		return nil, errors.New("pass threshold must be between 0 and 100")
+	}
+
+	// Distractors come from every word in the journey, not just this scenario
+	siblings, err := s.wordRepo.GetByJourneyID(scenario.JourneyID)
+	if err != nil {
+		return nil, err
+	}
+
+	words := make([]models.Word, len(scenario.Words))
+	copy(words, scenario.Words)
+	rand.Shuffle(len(words), func(i, j int) { words[i], words[j] = words[j], words[i] })
+
+	var questions []models.QuizQuestion
+	for _, word := range words {
+		if len(questions) == count {
+			break
+		}
+
+		questionType, ok := pickQuestionType(word, types, len(questions))
+		if !ok {
+			continue
+		}
+
+		options := buildOptions(word, siblings)
+		if len(options) < 2 {
+			continue
+		}
+		optionsJSON, err := json.Marshal(options)
+		if err != nil {
+			return nil, err
+		}
+
+		questions = append(questions, models.QuizQuestion{
+			WordID:        word.ID,
+			QuestionType:  questionType,
+			QuestionText:  questionText(questionType, word),
+			CorrectAnswer: word.TargetText,
+			Options:       string(optionsJSON),
+			DisplayOrder:  len(questions) + 1,
+		})
+	}
+
+	if len(questions) == 0 {
+		return nil, errors.New("no words are eligible for the requested question types")
+	}
+
+	title := req.Title
+	if title == "" {
+		title = scenario.Title + " Quiz"
+	}
+
+	quiz := &models.Quiz{
+		ScenarioID:    scenario.ID,
+		Title:         title,
+		PassThreshold: req.PassThreshold,
+		Questions:     questions,
+	}
+
+	if err := s.quizRepo.Create(quiz); err != nil {
+		return nil, err
+	}
+
+	return quiz, nil
+}
+
+func (s *quizService) GetQuiz(id, userID string, publishedOnly bool) (*QuizView, error) {
+	quiz, wordsByID, err := s.loadQuiz(id, userID, publishedOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	questions := make([]QuizQuestionView, 0, len(quiz.Questions))
+	for _, question := range quiz.Questions {
+		var options []string
+		if err := json.Unmarshal([]byte(question.Options), &options); err != nil {
+			return nil, fmt.Errorf("invalid options for question %s: %w", question.ID, err)
+		}
+
+		view := QuizQuestionView{
+			ID:           question.ID,
+			Type:         question.QuestionType,
+			QuestionText: question.QuestionText,
+			Options:      options,
+		}
+		if word, ok := wordsByID[question.WordID]; ok {
+			switch question.QuestionType {
+			case QuestionTypeAudioMatch:
+				view.AudioURL = signURL(s.signer, word.AudioURL)
+			case QuestionTypeImageMatch:
+				view.ImageURL = signURL(s.signer, word.ImageURL)
+				view.ImageSet = media.ImageSetFor(word.ImageURL).Map(s.signer.Sign)
+			}
+		}
+		questions = append(questions, view)
+	}
+
+	return &QuizView{
+		ID:            quiz.ID,
+		ScenarioID:    quiz.ScenarioID,
+		Title:         quiz.Title,
+		PassThreshold: quiz.PassThreshold,
+		Questions:     questions,
+	}, nil
+}
+
+func (s *quizService) SubmitQuiz(userID, quizID string, req SubmitQuizRequest, publishedOnly bool) (*QuizResult, error) {
+	quiz, wordsByID, err := s.loadQuiz(quizID, userID, publishedOnly)
+	if err != nil {
+		return nil, err
+	}
+	if len(quiz.Questions) == 0 {
+		return nil, errors.New("quiz has no questions")
+	}
+
+	submitted := make(map[string]string, len(req.Answers))
+	for _, answer := range req.Answers {
+		submitted[answer.QuestionID] = answer.Answer
+	}
+
+	// Unanswered questions are graded as incorrect
+	correct := 0
+	answers := make([]attemptAnswer, len(quiz.Questions))
+	feedback := make([]QuestionFeedback, len(quiz.Questions))
+	var missedIDs []string
+	results := make(map[string]bool, len(quiz.Questions))
+	for i, question := range quiz.Questions {
+		answer := submitted[question.ID]
+		isCorrect := strings.TrimSpace(answer) == question.CorrectAnswer
+		if isCorrect {
+			correct++
+		} else {
+			missedIDs = append(missedIDs, question.WordID)
+		}
+		// A word asked twice counts as recalled only if every answer was right
+		if prev, ok := results[question.WordID]; ok {
+			results[question.WordID] = prev && isCorrect
+		} else {
+			results[question.WordID] = isCorrect
+		}
+
+		answers[i] = attemptAnswer{QuestionID: question.ID, Answer: answer, IsCorrect: isCorrect}
+		feedback[i] = QuestionFeedback{
+			QuestionID:    question.ID,
+			Answer:        answer,
+			Correct:       isCorrect,
+			CorrectAnswer: question.CorrectAnswer,
+		}
+	}
+
+	answersJSON, err := json.Marshal(answers)
+	if err != nil {
+		return nil, err
+	}
+
+	total := len(quiz.Questions)
+	score := math.Round(float64(correct)/float64(total)*1000) / 10
+
+	attempt := &models.QuizAttempt{
+		UserID:         userID,
+		QuizID:         quiz.ID,
+		Score:          score,
+		TotalQuestions: total,
+		CorrectAnswers: correct,
+		Answers:        string(answersJSON),
+	}
+	if err := s.quizRepo.CreateAttempt(attempt); err != nil {
+		return nil, err
+	}
+
+	if err := s.progressService.RecordQuizResults(userID, results); err != nil {
+		return nil, err
+	}
+
+	missed := []MissedWord{}
+	seen := make(map[string]bool, len(missedIDs))
+	for _, id := range missedIDs {
+		word, ok := wordsByID[id]
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		missed = append(missed, MissedWord{ID: word.ID, TargetText: word.TargetText, SourceText: word.SourceText})
+	}
+
+	return &QuizResult{
+		AttemptID:      attempt.ID,
+		Score:          score,
+		TotalQuestions: total,
+		CorrectAnswers: correct,
+		PassThreshold:  quiz.PassThreshold,
+		Passed:         score >= quiz.PassThreshold,
+		Feedback:       feedback,
+		MissedWords:    missed,
+	}, nil
+}
+
+// loadQuiz fetches a quiz with its questions and the words they ask about.
+// With publishedOnly set the quiz comes from the learner's version of a
+// published journey; otherwise from the editable draft.
+func (s *quizService) loadQuiz(id, userID string, publishedOnly bool) (*models.Quiz, map[string]models.Word, error) {
+	if publishedOnly {
+		return s.loadPublishedQuiz(id, userID)
+	}
+
+	quiz, err := s.quizRepo.GetByIDWithQuestions(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("quiz not found")
+		}
+		return nil, nil, err
+	}
+
+	wordIDs := make([]string, len(quiz.Questions))
+	for i, question := range quiz.Questions {
+		wordIDs[i] = question.WordID
+	}
+	words, err := s.wordRepo.GetByIDs(wordIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	wordsByID := make(map[string]models.Word, len(words))
+	for _, word := range words {
+		wordsByID[word.ID] = word
+	}
+	return quiz, wordsByID, nil
+}
+
+func (s *quizService) loadPublishedQuiz(id, userID string) (*models.Quiz, map[string]models.Word, error) {
+	journeyID, err := s.quizRepo.GetJourneyID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("quiz not found")
+		}
+		return nil, nil, err
+	}
+	journey, err := s.journeyRepo.GetByID(journeyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, errors.New("quiz not found")
+		}
+		return nil, nil, err
+	}
+	if journey.Status != "published" {
+		return nil, nil, errors.New("quiz not found")
+	}
+
+	version, err := s.versionService.LearnerVersion(userID, journey, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	published, scenario, ok := version.Quiz(id)
+	if !ok {
+		return nil, nil, errors.New("quiz not found")
+	}
+
+	quiz := &models.Quiz{
+		ID:            published.ID,
+		ScenarioID:    scenario.ID,
+		Title:         published.Title,
+		PassThreshold: published.PassThreshold,
+	}
+	for _, question := range published.Questions {
+		quiz.Questions = append(quiz.Questions, models.QuizQuestion{
+			ID:            question.ID,
+			QuizID:        quiz.ID,
+			WordID:        question.WordID,
+			QuestionType:  question.QuestionType,
+			QuestionText:  question.QuestionText,
+			CorrectAnswer: question.CorrectAnswer,
+			Options:       question.Options,
+			DisplayOrder:  question.DisplayOrder,
+		})
+	}
+
+	wordsByID := make(map[string]models.Word, len(scenario.Words))
+	for _, word := range scenario.Words {
+		wordsByID[word.ID] = models.Word{
+			ID:         word.ID,
+			ScenarioID: scenario.ID,
+			TargetText: word.TargetText,
+			SourceText: word.SourceText,
+			ImageURL:   word.ImageURL,
+			AudioURL:   word.AudioURL,
+		}
+	}
+	return quiz, wordsByID, nil
+}
+
+// pickQuestionType rotates through the requested types starting at offset and
+// returns the first one the word has the media for
+func pickQuestionType(word models.Word, types []string, offset int) (string, bool) {
+	for i := range types {
+		t := types[(offset+i)%len(types)]
+		if supportsQuestionType(word, t) {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// supportsQuestionType reports whether the word has the translation or media
+// a question of the given type is built from
+func supportsQuestionType(word models.Word, questionType string) bool {
+	switch questionType {
+	case QuestionTypeMultipleChoice:
+		return word.SourceText != ""
+	case QuestionTypeAudioMatch:
+		return word.AudioURL != nil && *word.AudioURL != ""
+	case QuestionTypeImageMatch:
+		return word.ImageURL != nil && *word.ImageURL != ""
+	}
+	return false
+}
+
+// buildOptions returns the word's target text plus up to maxQuizOptions-1
+// distinct distractors drawn from the sibling words, in random order
+func buildOptions(word models.Word, siblings []models.Word) []string {
+	seen := map[string]bool{word.TargetText: true}
+	var distractors []string
+	for _, sibling := range siblings {
+		if seen[sibling.TargetText] {
+			continue
+		}
+		seen[sibling.TargetText] = true
+		distractors = append(distractors, sibling.TargetText)
+	}
+
+	rand.Shuffle(len(distractors), func(i, j int) { distractors[i], distractors[j] = distractors[j], distractors[i] })
+	if len(distractors) > maxQuizOptions-1 {
+		distractors = distractors[:maxQuizOptions-1]
+	}
+
+	options := append([]string{word.TargetText}, distractors...)
+	rand.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+	return options
+}
+
+func questionText(questionType string, word models.Word) string {
+	switch questionType {
+	case QuestionTypeAudioMatch:
+		return "Listen and choose the word you hear"
+	case QuestionTypeImageMatch:
+		return "Choose the word that matches the picture"
+	default:
+		return fmt.Sprintf("Which word means \"%s\"?", word.SourceText)
+	}
+}
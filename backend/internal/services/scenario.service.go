@@ -15,6 +15,8 @@ type ScenarioService interface {
 	UpdateScenario(id string, updates map[string]interface{}) (*models.Scenario, error)
 	DeleteScenario(id string) error
 	GetScenarioWithWords(id string) (*models.Scenario, error)
+	GetOwnerID(id string) (string, error)
+	ReorderScenarios(journeyID string, ids []string) ([]models.Scenario, error)
 }
 
 type scenarioService struct {
@@ -74,9 +76,6 @@ func (s *scenarioService) UpdateScenario(id string, updates map[string]interface
 	if description, ok := updates["description"].(string); ok {
 		scenario.Description = description
 	}
-	if displayOrder, ok := updates["displayOrder"].(float64); ok {
-		scenario.DisplayOrder = int(displayOrder)
-	}
 
 	if err := s.scenarioRepo.Update(scenario); err != nil {
 		return nil, err
@@ -108,3 +107,44 @@ func (s *scenarioService) GetScenarioWithWords(id string) (*models.Scenario, err
 	}
 	return scenario, nil
 }
+
+// GetOwnerID returns the ID of the user who created the scenario's journey
+func (s *scenarioService) GetOwnerID(id string) (string, error) {
+	scenario, err := s.scenarioRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("scenario not found")
+		}
+		return "", err
+	}
+
+	journey, err := s.journeyRepo.GetByID(scenario.JourneyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("journey not found")
+		}
+		return "", err
+	}
+
+	return journey.CreatedBy, nil
+}
+
+// ReorderScenarios renumbers a journey's scenarios to follow ids, which must
+// list every scenario in the journey exactly once
+func (s *scenarioService) ReorderScenarios(journeyID string, ids []string) ([]models.Scenario, error) {
+	if _, err := s.journeyRepo.GetByID(journeyID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("journey not found")
+		}
+		return nil, err
+	}
+
+	if err := s.scenarioRepo.Reorder(journeyID, ids); err != nil {
+		if errors.Is(err, repository.ErrOrderMismatch) {
+			return nil, errors.New("order must list every scenario in the journey exactly once")
+		}
+		return nil, err
+	}
+
+	return s.scenarioRepo.GetByJourneyID(journeyID)
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/learng/backend/internal/models"
+)
+
+// Recall quality grades on the SM-2 0-5 scale
+const (
+	qualityRecalled = 4 // Correct answer or card marked as known
+	qualityForgot   = 1 // Wrong answer or card marked as not known
+)
+
+const (
+	minEaseFactor     = 1.3
+	defaultEaseFactor = 2.5
+	// passingQuality is the lowest grade that counts as a successful recall
+	passingQuality = 3
+)
+
+// scheduleReview applies an SM-2 review with the given quality to the
+// progress record and sets the next due date relative to now
+func scheduleReview(progress *models.LearnerProgress, quality int, now time.Time) {
+	if progress.EaseFactor == 0 {
+		progress.EaseFactor = defaultEaseFactor
+	}
+
+	if quality >= passingQuality {
+		switch progress.Repetitions {
+		case 0:
+			progress.IntervalDays = 1
+		case 1:
+			progress.IntervalDays = 6
+		default:
+			progress.IntervalDays = int(math.Round(float64(progress.IntervalDays) * progress.EaseFactor))
+		}
+		progress.Repetitions++
+	} else {
+		// Forgetting a word that had been successfully reviewed is a lapse
+		if progress.Repetitions > 0 {
+			progress.LapseCount++
+		}
+		progress.Repetitions = 0
+		progress.IntervalDays = 1
+	}
+
+	q := float64(5 - quality)
+	progress.EaseFactor += 0.1 - q*(0.08+q*0.02)
+	if progress.EaseFactor < minEaseFactor {
+		progress.EaseFactor = minEaseFactor
+	}
+
+	due := now.AddDate(0, 0, progress.IntervalDays)
+	progress.DueAt = &due
+	progress.LastReviewedAt = &now
+}
+
+// startOfDay returns midnight of t's day in t's location
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/learng/backend/internal/models"
+)
+
+func TestScheduleReview(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		before  models.LearnerProgress
+		quality int
+		want    models.LearnerProgress
+	}{
+		{
+			name:    "first recall",
+			before:  models.LearnerProgress{},
+			quality: qualityRecalled,
+			want:    models.LearnerProgress{EaseFactor: 2.5, IntervalDays: 1, Repetitions: 1},
+		},
+		{
+			name:    "second recall",
+			before:  models.LearnerProgress{EaseFactor: 2.5, IntervalDays: 1, Repetitions: 1},
+			quality: qualityRecalled,
+			want:    models.LearnerProgress{EaseFactor: 2.5, IntervalDays: 6, Repetitions: 2},
+		},
+		{
+			name:    "later recall multiplies the interval",
+			before:  models.LearnerProgress{EaseFactor: 2.5, IntervalDays: 6, Repetitions: 2},
+			quality: qualityRecalled,
+			want:    models.LearnerProgress{EaseFactor: 2.5, IntervalDays: 15, Repetitions: 3},
+		},
+		{
+			name:    "perfect recall raises ease",
+			before:  models.LearnerProgress{EaseFactor: 2.5, IntervalDays: 6, Repetitions: 2},
+			quality: 5,
+			want:    models.LearnerProgress{EaseFactor: 2.6, IntervalDays: 15, Repetitions: 3},
+		},
+		{
+			name:    "forgetting a new word is not a lapse",
+			before:  models.LearnerProgress{},
+			quality: qualityForgot,
+			want:    models.LearnerProgress{EaseFactor: 1.96, IntervalDays: 1},
+		},
+		{
+			name:    "forgetting a reviewed word is a lapse",
+			before:  models.LearnerProgress{EaseFactor: 2.5, IntervalDays: 15, Repetitions: 3, LapseCount: 1},
+			quality: qualityForgot,
+			want:    models.LearnerProgress{EaseFactor: 1.96, IntervalDays: 1, LapseCount: 2},
+		},
+		{
+			name:    "ease never drops below the minimum",
+			before:  models.LearnerProgress{EaseFactor: 1.4, IntervalDays: 1, Repetitions: 1},
+			quality: qualityForgot,
+			want:    models.LearnerProgress{EaseFactor: minEaseFactor, IntervalDays: 1, LapseCount: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.before
+			scheduleReview(&got, tt.quality, now)
+
+			if math.Abs(got.EaseFactor-tt.want.EaseFactor) > 1e-9 {
+				t.Errorf("EaseFactor = %v, want %v", got.EaseFactor, tt.want.EaseFactor)
+			}
+			if got.IntervalDays != tt.want.IntervalDays || got.Repetitions != tt.want.Repetitions || got.LapseCount != tt.want.LapseCount {
+				t.Errorf("interval, repetitions, lapses = %d, %d, %d, want %d, %d, %d",
+					got.IntervalDays, got.Repetitions, got.LapseCount,
+					tt.want.IntervalDays, tt.want.Repetitions, tt.want.LapseCount)
+			}
+			if want := now.AddDate(0, 0, tt.want.IntervalDays); got.DueAt == nil || !got.DueAt.Equal(want) {
+				t.Errorf("DueAt = %v, want %v", got.DueAt, want)
+			}
+			if got.LastReviewedAt == nil || !got.LastReviewedAt.Equal(now) {
+				t.Errorf("LastReviewedAt = %v, want %v", got.LastReviewedAt, now)
+			}
+		})
+	}
+}
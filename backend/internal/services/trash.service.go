@@ -0,0 +1,176 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/learng/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// Trash item types
+const (
+	TrashTypeJourney  = "journey"
+	TrashTypeScenario = "scenario"
+	TrashTypeWord     = "word"
+)
+
+// TrashItem is a deleted journey, scenario or word that can still be restored
+type TrashItem struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	ParentID  string    `json:"parentId,omitempty"`
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"` // When the purge job removes it for good
+}
+
+type TrashService interface {
+	GetTrash(ownerID string) ([]TrashItem, error)
+	GetOwnerID(itemType, id string) (string, error)
+	RestoreJourney(id string) error
+	RestoreScenario(id string) error
+	RestoreWord(id string) error
+	PurgeExpired() (repository.PurgeCounts, error)
+	StartPurgeJob(interval time.Duration)
+}
+
+type trashService struct {
+	trashRepo repository.TrashRepository
+	retention time.Duration
+}
+
+func NewTrashService(trashRepo repository.TrashRepository, retention time.Duration) TrashService {
+	return &trashService{
+		trashRepo: trashRepo,
+		retention: retention,
+	}
+}
+
+// GetTrash lists what the owner deleted directly, newest first. Rows removed
+// by a cascade are restored with their parent and are not listed.
+func (s *trashService) GetTrash(ownerID string) ([]TrashItem, error) {
+	entries, err := s.trashRepo.ListByOwner(ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]TrashItem, 0, len(entries))
+	for _, entry := range entries {
+		items = append(items, TrashItem{
+			Type:      entry.Type,
+			ID:        entry.ID,
+			Title:     entry.Title,
+			ParentID:  entry.ParentID,
+			DeletedAt: entry.DeletedAt,
+			PurgeAt:   entry.DeletedAt.Add(s.retention),
+		})
+	}
+	return items, nil
+}
+
+// GetOwnerID returns the ID of the user who created the journey a deleted
+// item belongs to
+func (s *trashService) GetOwnerID(itemType, id string) (string, error) {
+	switch itemType {
+	case TrashTypeJourney:
+		journey, err := s.trashRepo.GetDeletedJourney(id)
+		if err != nil {
+			return "", notFoundErr(err, "journey not found")
+		}
+		return journey.CreatedBy, nil
+	case TrashTypeScenario:
+		scenario, err := s.trashRepo.GetDeletedScenario(id)
+		if err != nil {
+			return "", notFoundErr(err, "scenario not found")
+		}
+		return scenario.Journey.CreatedBy, nil
+	case TrashTypeWord:
+		word, err := s.trashRepo.GetDeletedWord(id)
+		if err != nil {
+			return "", notFoundErr(err, "word not found")
+		}
+		return word.Scenario.Journey.CreatedBy, nil
+	}
+	return "", errors.New("invalid trash item type")
+}
+
+func (s *trashService) RestoreJourney(id string) error {
+	journey, err := s.trashRepo.GetDeletedJourney(id)
+	if err != nil {
+		return notFoundErr(err, "journey not found")
+	}
+	return s.trashRepo.RestoreJourney(journey)
+}
+
+// RestoreScenario restores a scenario into its journey, which must be live
+func (s *trashService) RestoreScenario(id string) error {
+	scenario, err := s.trashRepo.GetDeletedScenario(id)
+	if err != nil {
+		return notFoundErr(err, "scenario not found")
+	}
+	if scenario.Journey.DeletedAt.Valid {
+		return errors.New("the scenario's journey is deleted; restore the journey first")
+	}
+	return s.trashRepo.RestoreScenario(scenario)
+}
+
+// RestoreWord restores a word into its scenario, which must be live
+func (s *trashService) RestoreWord(id string) error {
+	word, err := s.trashRepo.GetDeletedWord(id)
+	if err != nil {
+		return notFoundErr(err, "word not found")
+	}
+	if word.Scenario.DeletedAt.Valid || word.Scenario.Journey.DeletedAt.Valid {
+		return errors.New("the word's scenario is deleted; restore the scenario first")
+	}
+	return s.trashRepo.RestoreWord(word)
+}
+
+// PurgeExpired hard-deletes everything that has been in the trash longer than
+// the retention window
+func (s *trashService) PurgeExpired() (repository.PurgeCounts, error) {
+	return s.trashRepo.Purge(time.Now().UTC().Add(-s.retention))
+}
+
+// StartPurgeJob runs PurgeExpired now and then on every interval tick in the
+// background. A non-positive interval disables the job.
+func (s *trashService) StartPurgeJob(interval time.Duration) {
+	if interval <= 0 {
+		log.Println("Trash purge job disabled")
+		return
+	}
+
+	purge := func() {
+		counts, err := s.PurgeExpired()
+		if err != nil {
+			log.Printf("Trash purge failed: %v", err)
+			return
+		}
+		var total int64
+		for _, n := range counts {
+			total += n
+		}
+		if total > 0 {
+			log.Printf("Trash purge removed %d rows: %v", total, counts)
+		}
+	}
+
+	go func() {
+		purge()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			purge()
+		}
+	}()
+}
+
+// notFoundErr maps gorm's not-found error to a service error message
+func notFoundErr(err error, msg string) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New(msg)
+	}
+	return err
+}
@@ -0,0 +1,610 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/learng/backend/internal/models"
+	"github.com/learng/backend/internal/repository"
+	"gorm.io/gorm"
+)
+
+// JourneyVersionView is a published version of a journey. Content is only
+// filled in when a single version is requested.
+type JourneyVersionView struct {
+	ID             string         `json:"id"`
+	JourneyID      string         `json:"journeyId"`
+	Version        int            `json:"version"`
+	PublishedBy    string         `json:"publishedBy"`
+	RestoredFromID *string        `json:"restoredFromId"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	Live           bool           `json:"live"`
+	Content        *BundleJourney `json:"content,omitempty"`
+}
+
+// JourneyDiff lists what changed in the draft since a published version
+type JourneyDiff struct {
+	VersionID  string        `json:"versionId"`
+	Version    int           `json:"version"`
+	HasChanges bool          `json:"hasChanges"`
+	Journey    []FieldChange `json:"journey"`
+	Scenarios  DiffSet       `json:"scenarios"`
+	Words      DiffSet       `json:"words"`
+	Quizzes    DiffSet       `json:"quizzes"`
+}
+
+// DiffSet groups the added, removed and changed items of one kind
+type DiffSet struct {
+	Added   []DiffItem `json:"added"`
+	Removed []DiffItem `json:"removed"`
+	Changed []DiffItem `json:"changed"`
+}
+
+type DiffItem struct {
+	ID         string        `json:"id"`
+	Title      string        `json:"title"`
+	ScenarioID string        `json:"scenarioId,omitempty"`
+	Changes    []FieldChange `json:"changes,omitempty"`
+}
+
+// FieldChange is a field whose value differs between the version and the draft
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// PinnedVersion is the published version of a journey a learner works
+// through, with its content
+type PinnedVersion struct {
+	VersionID   string
+	Version     int
+	LiveVersion int
+	Content     *BundleJourney
+}
+
+// UpdateAvailable reports whether a newer version than the learner's is live
+func (v *PinnedVersion) UpdateAvailable() bool {
+	return v.Version < v.LiveVersion
+}
+
+func (v *PinnedVersion) Scenario(id string) (*BundleScenario, bool) {
+	for i := range v.Content.Scenarios {
+		if v.Content.Scenarios[i].ID == id {
+			return &v.Content.Scenarios[i], true
+		}
+	}
+	return nil, false
+}
+
+// Word finds a word in the version together with its scenario
+func (v *PinnedVersion) Word(id string) (*BundleWord, *BundleScenario, bool) {
+	for i := range v.Content.Scenarios {
+		scenario := &v.Content.Scenarios[i]
+		for j := range scenario.Words {
+			if scenario.Words[j].ID == id {
+				return &scenario.Words[j], scenario, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// Quiz finds a quiz in the version together with its scenario
+func (v *PinnedVersion) Quiz(id string) (*BundleQuiz, *BundleScenario, bool) {
+	for i := range v.Content.Scenarios {
+		scenario := &v.Content.Scenarios[i]
+		for j := range scenario.Quizzes {
+			if scenario.Quizzes[j].ID == id {
+				return &scenario.Quizzes[j], scenario, true
+			}
+		}
+	}
+	return nil, nil, false
+}
+
+// WordIDs returns the IDs of every word in the version
+func (v *PinnedVersion) WordIDs() []string {
+	var ids []string
+	for _, scenario := range v.Content.Scenarios {
+		for _, word := range scenario.Words {
+			ids = append(ids, word.ID)
+		}
+	}
+	return ids
+}
+
+type VersionService interface {
+	ListVersions(journeyID string) ([]JourneyVersionView, error)
+	GetVersion(journeyID, versionID string) (*JourneyVersionView, error)
+	DiffDraft(journeyID, versionID string) (*JourneyDiff, error)
+	Rollback(journeyID, versionID, userID string) (*JourneyVersionView, error)
+	LearnerVersion(userID string, journey *models.Journey, pin bool) (*PinnedVersion, error)
+	UpgradeLearner(userID, journeyID string) (*PinnedVersion, error)
+	EnsureLiveVersions() error
+}
+
+type versionService struct {
+	versionRepo  repository.VersionRepository
+	journeyRepo  repository.JourneyRepository
+	scenarioRepo repository.ScenarioRepository
+	wordRepo     repository.WordRepository
+	quizRepo     repository.QuizRepository
+	progressRepo repository.ProgressRepository
+}
+
+func NewVersionService(
+	versionRepo repository.VersionRepository,
+	journeyRepo repository.JourneyRepository,
+	scenarioRepo repository.ScenarioRepository,
+	wordRepo repository.WordRepository,
+	quizRepo repository.QuizRepository,
+	progressRepo repository.ProgressRepository,
+) VersionService {
+	return &versionService{
+		versionRepo:  versionRepo,
+		journeyRepo:  journeyRepo,
+		scenarioRepo: scenarioRepo,
+		wordRepo:     wordRepo,
+		quizRepo:     quizRepo,
+		progressRepo: progressRepo,
+	}
+}
+
+// ListVersions returns a journey's versions, newest first
+func (s *versionService) ListVersions(journeyID string) ([]JourneyVersionView, error) {
+	journey, err := s.getJourney(journeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := s.versionRepo.GetByJourneyID(journeyID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]JourneyVersionView, len(versions))
+	for i := range versions {
+		views[i] = versionView(journey, &versions[i], nil)
+	}
+	return views, nil
+}
+
+// GetVersion returns a version of the journey with its content
+func (s *versionService) GetVersion(journeyID, versionID string) (*JourneyVersionView, error) {
+	journey, err := s.getJourney(journeyID)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.getVersion(journeyID, versionID)
+	if err != nil {
+		return nil, err
+	}
+	content, err := decodeSnapshot(version)
+	if err != nil {
+		return nil, err
+	}
+	view := versionView(journey, version, content)
+	return &view, nil
+}
+
+// DiffDraft compares the journey's current draft with a version, or with the
+// live version when versionID is empty
+func (s *versionService) DiffDraft(journeyID, versionID string) (*JourneyDiff, error) {
+	journey, err := s.getJourney(journeyID)
+	if err != nil {
+		return nil, err
+	}
+	if versionID == "" {
+		if journey.LiveVersionID == nil {
+			return nil, errors.New("journey has no published version")
+		}
+		versionID = *journey.LiveVersionID
+	}
+	version, err := s.getVersion(journeyID, versionID)
+	if err != nil {
+		return nil, err
+	}
+	base, err := decodeSnapshot(version)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := loadJourneyTree(s.scenarioRepo, s.wordRepo, s.quizRepo, journeyID)
+	if err != nil {
+		return nil, err
+	}
+	draft := snapshotJourney(journey, tree)
+
+	diff := diffJourneys(base, &draft)
+	diff.VersionID = version.ID
+	diff.Version = version.Version
+	return diff, nil
+}
+
+// Rollback makes an earlier version live again by publishing a copy of it as
+// the newest version. The draft is left as it is, so the diff shows what a
+// republish would bring back.
+func (s *versionService) Rollback(journeyID, versionID, userID string) (*JourneyVersionView, error) {
+	journey, err := s.getJourney(journeyID)
+	if err != nil {
+		return nil, err
+	}
+	if journey.Status != JourneyPublished {
+		return nil, errors.New("only a published journey can be rolled back")
+	}
+	target, err := s.getVersion(journeyID, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if journey.LiveVersionID != nil && *journey.LiveVersionID == target.ID {
+		return nil, errors.New("version is already live")
+	}
+
+	restored := &models.JourneyVersion{
+		Snapshot:       target.Snapshot,
+		PublishedBy:    userID,
+		RestoredFromID: &target.ID,
+	}
+	ok, err := s.versionRepo.Publish(journeyID, JourneyPublished, map[string]interface{}{
+		"published_at": time.Now(),
+	}, restored)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New("journey status changed concurrently; reload and try again")
+	}
+
+	journey, err = s.getJourney(journeyID)
+	if err != nil {
+		return nil, err
+	}
+	view := versionView(journey, restored, nil)
+	return &view, nil
+}
+
+// LearnerVersion returns the version of a published journey the learner is
+// working through. Learners stay on the version they started until they have
+// seen every word in it, then move to the live version. With pin set, a
+// learner who has not started yet is pinned to the live version.
+func (s *versionService) LearnerVersion(userID string, journey *models.Journey, pin bool) (*PinnedVersion, error) {
+	if journey.LiveVersionID == nil {
+		return nil, errors.New("journey has no published version")
+	}
+	live, err := s.versionRepo.GetByID(*journey.LiveVersionID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := live
+	existing, err := s.versionRepo.GetPin(userID, journey.ID)
+	switch {
+	case err == nil:
+		current = &existing.Version
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		existing = nil
+	default:
+		return nil, err
+	}
+
+	content, err := decodeSnapshot(current)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.ID != live.ID {
+		finished, err := s.hasFinished(userID, content)
+		if err != nil {
+			return nil, err
+		}
+		if finished {
+			current = live
+			if content, err = decodeSnapshot(live); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if (existing == nil && pin) || (existing != nil && existing.VersionID != current.ID) {
+		if err := s.versionRepo.UpsertPin(&models.LearnerJourneyPin{
+			UserID:    userID,
+			JourneyID: journey.ID,
+			VersionID: current.ID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &PinnedVersion{
+		VersionID:   current.ID,
+		Version:     current.Version,
+		LiveVersion: live.Version,
+		Content:     content,
+	}, nil
+}
+
+// UpgradeLearner moves the learner to the live version of a published journey
+// before they have finished their current one
+func (s *versionService) UpgradeLearner(userID, journeyID string) (*PinnedVersion, error) {
+	journey, err := s.getJourney(journeyID)
+	if err != nil {
+		return nil, err
+	}
+	if journey.Status != JourneyPublished || journey.LiveVersionID == nil {
+		return nil, errors.New("journey not found")
+	}
+
+	if err := s.versionRepo.UpsertPin(&models.LearnerJourneyPin{
+		UserID:    userID,
+		JourneyID: journeyID,
+		VersionID: *journey.LiveVersionID,
+	}); err != nil {
+		return nil, err
+	}
+	return s.LearnerVersion(userID, journey, true)
+}
+
+// EnsureLiveVersions snapshots published journeys that have no version yet,
+// so that journeys published before versioning keep working for learners
+func (s *versionService) EnsureLiveVersions() error {
+	journeys, err := s.journeyRepo.GetPublishedUnversioned()
+	if err != nil {
+		return err
+	}
+
+	for i := range journeys {
+		journey := &journeys[i]
+		tree, err := loadJourneyTree(s.scenarioRepo, s.wordRepo, s.quizRepo, journey.ID)
+		if err != nil {
+			return err
+		}
+		version, err := newJourneyVersion(journey, tree, journey.CreatedBy)
+		if err != nil {
+			return err
+		}
+		if _, err := s.versionRepo.Publish(journey.ID, JourneyPublished, map[string]interface{}{
+			"published_at": gorm.Expr("COALESCE(published_at, ?)", time.Now()),
+		}, version); err != nil {
+			return err
+		}
+		log.Printf("Created version %d of published journey %s", version.Version, journey.ID)
+	}
+	return nil
+}
+
+// hasFinished reports whether the learner has moved every word of the
+// version past 'new'
+func (s *versionService) hasFinished(userID string, content *BundleJourney) (bool, error) {
+	pinned := &PinnedVersion{Content: content}
+	wordIDs := pinned.WordIDs()
+	progress, err := s.progressRepo.GetByUserAndWordIDs(userID, wordIDs)
+	if err != nil {
+		return false, err
+	}
+
+	seen := 0
+	for _, p := range progress {
+		if p.MasteryLevel != MasteryNew {
+			seen++
+		}
+	}
+	return seen >= len(wordIDs), nil
+}
+
+func (s *versionService) getJourney(id string) (*models.Journey, error) {
+	journey, err := s.journeyRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("journey not found")
+		}
+		return nil, err
+	}
+	return journey, nil
+}
+
+// getVersion loads a version, treating one of another journey as missing
+func (s *versionService) getVersion(journeyID, versionID string) (*models.JourneyVersion, error) {
+	version, err := s.versionRepo.GetByID(versionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("version not found")
+		}
+		return nil, err
+	}
+	if version.JourneyID != journeyID {
+		return nil, errors.New("version not found")
+	}
+	return version, nil
+}
+
+// newJourneyVersion snapshots a journey's tree into an unsaved version
+func newJourneyVersion(journey *models.Journey, tree *journeyTree, publishedBy string) (*models.JourneyVersion, error) {
+	snapshot, err := json.Marshal(snapshotJourney(journey, tree))
+	if err != nil {
+		return nil, err
+	}
+	return &models.JourneyVersion{
+		Snapshot:    string(snapshot),
+		PublishedBy: publishedBy,
+	}, nil
+}
+
+func decodeSnapshot(version *models.JourneyVersion) (*BundleJourney, error) {
+	var content BundleJourney
+	if err := json.Unmarshal([]byte(version.Snapshot), &content); err != nil {
+		return nil, fmt.Errorf("invalid snapshot for version %s: %w", version.ID, err)
+	}
+	return &content, nil
+}
+
+func versionView(journey *models.Journey, version *models.JourneyVersion, content *BundleJourney) JourneyVersionView {
+	return JourneyVersionView{
+		ID:             version.ID,
+		JourneyID:      version.JourneyID,
+		Version:        version.Version,
+		PublishedBy:    version.PublishedBy,
+		RestoredFromID: version.RestoredFromID,
+		CreatedAt:      version.CreatedAt,
+		Live:           journey.LiveVersionID != nil && *journey.LiveVersionID == version.ID,
+		Content:        content,
+	}
+}
+
+// diffJourneys compares a published snapshot with the draft
+func diffJourneys(base, draft *BundleJourney) *JourneyDiff {
+	diff := &JourneyDiff{
+		Journey:   []FieldChange{},
+		Scenarios: newDiffSet(),
+		Words:     newDiffSet(),
+		Quizzes:   newDiffSet(),
+	}
+
+	compareField(&diff.Journey, "title", base.Title, draft.Title)
+	compareField(&diff.Journey, "description", base.Description, draft.Description)
+	compareField(&diff.Journey, "sourceLanguage", base.SourceLanguage, draft.SourceLanguage)
+	compareField(&diff.Journey, "targetLanguage", base.TargetLanguage, draft.TargetLanguage)
+
+	type wordEntry struct {
+		word       BundleWord
+		scenarioID string
+	}
+	type quizEntry struct {
+		quiz       BundleQuiz
+		scenarioID string
+	}
+	indexJourney := func(journey *BundleJourney) (map[string]BundleScenario, map[string]wordEntry, map[string]quizEntry) {
+		scenarios := make(map[string]BundleScenario)
+		words := make(map[string]wordEntry)
+		quizzes := make(map[string]quizEntry)
+		for _, scenario := range journey.Scenarios {
+			scenarios[scenario.ID] = scenario
+			for _, word := range scenario.Words {
+				words[word.ID] = wordEntry{word, scenario.ID}
+			}
+			for _, quiz := range scenario.Quizzes {
+				quizzes[quiz.ID] = quizEntry{quiz, scenario.ID}
+			}
+		}
+		return scenarios, words, quizzes
+	}
+	baseScenarios, baseWords, baseQuizzes := indexJourney(base)
+	draftScenarios, draftWords, draftQuizzes := indexJourney(draft)
+
+	// Walk the base for removals and changes, then the draft for additions,
+	// both in display order
+	for _, scenario := range base.Scenarios {
+		item := DiffItem{ID: scenario.ID, Title: scenario.Title}
+		current, ok := draftScenarios[scenario.ID]
+		if !ok {
+			diff.Scenarios.Removed = append(diff.Scenarios.Removed, item)
+			continue
+		}
+		compareField(&item.Changes, "title", scenario.Title, current.Title)
+		compareField(&item.Changes, "description", scenario.Description, current.Description)
+		compareField(&item.Changes, "displayOrder", scenario.DisplayOrder, current.DisplayOrder)
+		if len(item.Changes) > 0 {
+			diff.Scenarios.Changed = append(diff.Scenarios.Changed, item)
+		}
+	}
+	for _, scenario := range draft.Scenarios {
+		if _, ok := baseScenarios[scenario.ID]; !ok {
+			diff.Scenarios.Added = append(diff.Scenarios.Added, DiffItem{ID: scenario.ID, Title: scenario.Title})
+		}
+	}
+
+	for _, scenario := range base.Scenarios {
+		for _, word := range scenario.Words {
+			item := DiffItem{ID: word.ID, Title: word.TargetText, ScenarioID: scenario.ID}
+			current, ok := draftWords[word.ID]
+			if !ok {
+				diff.Words.Removed = append(diff.Words.Removed, item)
+				continue
+			}
+			compareField(&item.Changes, "scenarioId", scenario.ID, current.scenarioID)
+			compareField(&item.Changes, "targetText", word.TargetText, current.word.TargetText)
+			compareField(&item.Changes, "sourceText", word.SourceText, current.word.SourceText)
+			compareField(&item.Changes, "displayOrder", word.DisplayOrder, current.word.DisplayOrder)
+			compareField(&item.Changes, "imageUrl", stringValue(word.ImageURL), stringValue(current.word.ImageURL))
+			compareField(&item.Changes, "audioUrl", stringValue(word.AudioURL), stringValue(current.word.AudioURL))
+			if len(item.Changes) > 0 {
+				diff.Words.Changed = append(diff.Words.Changed, item)
+			}
+		}
+		for _, quiz := range scenario.Quizzes {
+			item := DiffItem{ID: quiz.ID, Title: quiz.Title, ScenarioID: scenario.ID}
+			current, ok := draftQuizzes[quiz.ID]
+			if !ok {
+				diff.Quizzes.Removed = append(diff.Quizzes.Removed, item)
+				continue
+			}
+			compareField(&item.Changes, "title", quiz.Title, current.quiz.Title)
+			compareField(&item.Changes, "passThreshold", quiz.PassThreshold, current.quiz.PassThreshold)
+			if !sameQuestions(quiz.Questions, current.quiz.Questions) {
+				item.Changes = append(item.Changes, FieldChange{
+					Field: "questions",
+					From:  len(quiz.Questions),
+					To:    len(current.quiz.Questions),
+				})
+			}
+			if len(item.Changes) > 0 {
+				diff.Quizzes.Changed = append(diff.Quizzes.Changed, item)
+			}
+		}
+	}
+	for _, scenario := range draft.Scenarios {
+		for _, word := range scenario.Words {
+			if _, ok := baseWords[word.ID]; !ok {
+				diff.Words.Added = append(diff.Words.Added, DiffItem{ID: word.ID, Title: word.TargetText, ScenarioID: scenario.ID})
+			}
+		}
+		for _, quiz := range scenario.Quizzes {
+			if _, ok := baseQuizzes[quiz.ID]; !ok {
+				diff.Quizzes.Added = append(diff.Quizzes.Added, DiffItem{ID: quiz.ID, Title: quiz.Title, ScenarioID: scenario.ID})
+			}
+		}
+	}
+
+	diff.HasChanges = len(diff.Journey) > 0 ||
+		diff.Scenarios.changed() || diff.Words.changed() || diff.Quizzes.changed()
+	return diff
+}
+
+func newDiffSet() DiffSet {
+	return DiffSet{Added: []DiffItem{}, Removed: []DiffItem{}, Changed: []DiffItem{}}
+}
+
+func (d DiffSet) changed() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// compareField records a change when from and to differ. Both must be of the
+// same comparable type.
+func compareField(changes *[]FieldChange, field string, from, to interface{}) {
+	if from != to {
+		*changes = append(*changes, FieldChange{Field: field, From: from, To: to})
+	}
+}
+
+func sameQuestions(a, b []BundleQuizQuestion) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		x, y := a[i], b[i]
+		x.ID, y.ID = "", ""
+		if x != y {
+			return false
+		}
+	}
+	return true
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
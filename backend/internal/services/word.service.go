@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 
+	"github.com/learng/backend/internal/media"
 	"github.com/learng/backend/internal/models"
 	"github.com/learng/backend/internal/repository"
 	"gorm.io/gorm"
@@ -14,17 +15,24 @@ type WordService interface {
 	GetWordsByScenarioID(scenarioID string) ([]models.Word, error)
 	UpdateWord(id string, updates map[string]interface{}) (*models.Word, error)
 	DeleteWord(id string) error
+	GetOwnerID(id string) (string, error)
+	ImportWords(scenarioID string, req WordImportRequest) (*WordImportResult, error)
+	ReorderWords(scenarioID string, ids []string) ([]models.Word, error)
 }
 
 type wordService struct {
 	wordRepo     repository.WordRepository
 	scenarioRepo repository.ScenarioRepository
+	journeyRepo  repository.JourneyRepository
+	assetRepo    repository.MediaAssetRepository
 }
 
-func NewWordService(wordRepo repository.WordRepository, scenarioRepo repository.ScenarioRepository) WordService {
+func NewWordService(wordRepo repository.WordRepository, scenarioRepo repository.ScenarioRepository, journeyRepo repository.JourneyRepository, assetRepo repository.MediaAssetRepository) WordService {
 	return &wordService{
 		wordRepo:     wordRepo,
 		scenarioRepo: scenarioRepo,
+		journeyRepo:  journeyRepo,
+		assetRepo:    assetRepo,
 	}
 }
 
@@ -46,6 +54,10 @@ func (s *wordService) CreateWord(word *models.Word) error {
 		return err
 	}
 
+	if err := s.linkMedia(word); err != nil {
+		return err
+	}
+
 	return s.wordRepo.Create(word)
 }
 
@@ -73,19 +85,28 @@ func (s *wordService) UpdateWord(id string, updates map[string]interface{}) (*mo
 	if sourceText, ok := updates["sourceText"].(string); ok {
 		word.SourceText = sourceText
 	}
-	if displayOrder, ok := updates["displayOrder"].(float64); ok {
-		word.DisplayOrder = int(displayOrder)
-	}
+	// A new URL replaces the linked asset; a new asset ID replaces the URL
 	if imageURL, ok := updates["imageUrl"].(string); ok {
 		word.ImageURL = &imageURL
+		word.ImageAssetID = nil
 	}
 	if audioURL, ok := updates["audioUrl"].(string); ok {
 		word.AudioURL = &audioURL
+		word.AudioAssetID = nil
+	}
+	if imageAssetID, ok := updates["imageAssetId"].(string); ok {
+		word.ImageAssetID = &imageAssetID
+	}
+	if audioAssetID, ok := updates["audioAssetId"].(string); ok {
+		word.AudioAssetID = &audioAssetID
 	}
 	if generationMethod, ok := updates["generationMethod"].(string); ok {
 		word.GenerationMethod = generationMethod
 	}
 
+	if err := s.linkMedia(word); err != nil {
+		return nil, err
+	}
 	if err := s.wordRepo.Update(word); err != nil {
 		return nil, err
 	}
@@ -105,3 +126,101 @@ func (s *wordService) DeleteWord(id string) error {
 
 	return s.wordRepo.Delete(id)
 }
+
+// GetOwnerID returns the ID of the user who created the word's journey
+func (s *wordService) GetOwnerID(id string) (string, error) {
+	word, err := s.wordRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("word not found")
+		}
+		return "", err
+	}
+
+	scenario, err := s.scenarioRepo.GetByID(word.ScenarioID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("scenario not found")
+		}
+		return "", err
+	}
+
+	journey, err := s.journeyRepo.GetByID(scenario.JourneyID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("journey not found")
+		}
+		return "", err
+	}
+
+	return journey.CreatedBy, nil
+}
+
+// ReorderWords renumbers a scenario's words to follow ids, which must list
+// every word in the scenario exactly once
+func (s *wordService) ReorderWords(scenarioID string, ids []string) ([]models.Word, error) {
+	if _, err := s.scenarioRepo.GetByID(scenarioID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("scenario not found")
+		}
+		return nil, err
+	}
+
+	if err := s.wordRepo.Reorder(scenarioID, ids); err != nil {
+		if errors.Is(err, repository.ErrOrderMismatch) {
+			return nil, errors.New("order must list every word in the scenario exactly once")
+		}
+		return nil, err
+	}
+
+	return s.wordRepo.GetByScenarioID(scenarioID)
+}
+
+// linkMedia links the word's image and audio to their assets. A word given an
+// asset ID takes the asset's URL; a word given the URL of an asset is linked
+// to it. Other URLs, such as external links, are kept without an asset.
+// Signed URLs from API responses are stored in their plain form.
+func (s *wordService) linkMedia(word *models.Word) error {
+	word.ImageURL = unsignedURL(word.ImageURL)
+	word.AudioURL = unsignedURL(word.AudioURL)
+
+	var err error
+	word.ImageAssetID, word.ImageURL, err = s.resolveMedia(media.KindImage, word.ImageAssetID, word.ImageURL)
+	if err != nil {
+		return err
+	}
+	word.AudioAssetID, word.AudioURL, err = s.resolveMedia(media.KindAudio, word.AudioAssetID, word.AudioURL)
+	return err
+}
+
+// resolveMedia returns the asset ID and URL a word stores for one kind of media
+func (s *wordService) resolveMedia(kind string, assetID, url *string) (*string, *string, error) {
+	if assetID != nil && *assetID != "" {
+		asset, err := s.assetRepo.GetByID(*assetID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, nil, errors.New(kind + " asset not found")
+			}
+			return nil, nil, err
+		}
+		if asset.Kind != kind {
+			return nil, nil, errors.New(kind + " asset not found")
+		}
+		return &asset.ID, &asset.URL, nil
+	}
+
+	if url == nil || *url == "" {
+		return nil, url, nil
+	}
+	asset, err := s.assetRepo.GetByURL(*url)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, url, nil
+		}
+		return nil, nil, err
+	}
+	if asset.Kind != kind {
+		return nil, url, nil
+	}
+	return &asset.ID, url, nil
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/learng/backend/internal/models"
+	"gorm.io/gorm"
+)
+
+const (
+	maxImportRows       = 1000
+	maxImportTextLength = 200
+)
+
+// WordImportRequest describes a bulk word import from CSV/TSV data
+type WordImportRequest struct {
+	Text      string            `json:"text"`      // Raw CSV/TSV, e.g. pasted from a spreadsheet
+	Format    string            `json:"format"`    // 'csv' | 'tsv' | '' to auto-detect
+	HasHeader bool              `json:"hasHeader"` // First row holds column names
+	Columns   WordImportColumns `json:"columns"`
+	DryRun    bool              `json:"dryRun"` // Validate only, insert nothing
+}
+
+// WordImportColumns maps word fields to columns, each given as a header name
+// or a 0-based column index. Unset fields fall back to header name detection,
+// or to the order target, source, image, audio when there is no header.
+type WordImportColumns struct {
+	TargetText string `json:"targetText"`
+	SourceText string `json:"sourceText"`
+	ImageURL   string `json:"imageUrl"`
+	AudioURL   string `json:"audioUrl"`
+}
+
+// WordImportResult reports the outcome of an import
+type WordImportResult struct {
+	DryRun    bool              `json:"dryRun"`
+	TotalRows int               `json:"totalRows"`
+	ValidRows int               `json:"validRows"`
+	Imported  int               `json:"imported"`
+	Errors    []WordImportError `json:"errors"`
+	Words     []models.Word     `json:"words"`
+}
+
+// WordImportError is a validation failure on one input row
+type WordImportError struct {
+	Row     int    `json:"row"` // 1-based line in the input, counting the header
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// importColumnAliases are the header names recognised for each field
+var importColumnAliases = map[string][]string{
+	"targetText": {"targettext", "target", "target text", "word"},
+	"sourceText": {"sourcetext", "source", "source text", "translation", "meaning"},
+	"imageUrl":   {"imageurl", "image", "image url"},
+	"audioUrl":   {"audiourl", "audio", "audio url"},
+}
+
+// ImportWords validates CSV/TSV rows and, unless DryRun is set, appends every
+// valid row to the scenario in a single transaction
+func (s *wordService) ImportWords(scenarioID string, req WordImportRequest) (*WordImportResult, error) {
+	if _, err := s.scenarioRepo.GetByID(scenarioID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("scenario not found")
+		}
+		return nil, err
+	}
+
+	records, err := parseImportText(req.Text, req.Format)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("no rows to import")
+	}
+
+	var header []string
+	firstRow := 1
+	if req.HasHeader {
+		header = records[0]
+		records = records[1:]
+		firstRow = 2
+	}
+	if len(records) > maxImportRows {
+		return nil, fmt.Errorf("too many rows (max %d)", maxImportRows)
+	}
+
+	columns, err := resolveImportColumns(req.Columns, header)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.wordRepo.GetByScenarioID(scenarioID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]int, len(existing))
+	for _, word := range existing {
+		seen[strings.ToLower(word.TargetText)] = 0
+	}
+
+	result := &WordImportResult{
+		DryRun: req.DryRun,
+		Errors: []WordImportError{},
+		Words:  []models.Word{},
+	}
+
+	var words []models.Word
+	for i, record := range records {
+		row := firstRow + i
+		if isBlankRecord(record) {
+			continue
+		}
+		result.TotalRows++
+
+		field := func(name string) string {
+			idx, ok := columns[name]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+
+		word, rowErrors := buildImportWord(row, field("targetText"), field("sourceText"), field("imageUrl"), field("audioUrl"))
+		if word != nil {
+			key := strings.ToLower(word.TargetText)
+			if prevRow, dup := seen[key]; dup {
+				msg := "duplicates an existing word in this scenario"
+				if prevRow > 0 {
+					msg = fmt.Sprintf("duplicates row %d", prevRow)
+				}
+				rowErrors = append(rowErrors, WordImportError{Row: row, Field: "targetText", Message: msg})
+			} else {
+				seen[key] = row
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			result.Errors = append(result.Errors, rowErrors...)
+			continue
+		}
+		words = append(words, *word)
+	}
+	result.ValidRows = len(words)
+
+	if req.DryRun || len(words) == 0 {
+		return result, nil
+	}
+
+	for i := range words {
+		if err := s.linkMedia(&words[i]); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.wordRepo.AppendBatch(scenarioID, words); err != nil {
+		return nil, err
+	}
+	result.Imported = len(words)
+	result.Words = words
+
+	return result, nil
+}
+
+// parseImportText parses CSV or TSV, detecting tabs when format is empty
+func parseImportText(text, format string) ([][]string, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("no rows to import")
+	}
+
+	// Spreadsheets put a BOM on exported CSV files
+	text = strings.TrimPrefix(text, "\ufeff")
+
+	var comma rune
+	switch strings.ToLower(format) {
+	case "csv":
+		comma = ','
+	case "tsv":
+		comma = '\t'
+	case "":
+		firstLine, _, _ := strings.Cut(text, "\n")
+		comma = ','
+		if strings.Contains(firstLine, "\t") {
+			comma = '\t'
+		}
+	default:
+		return nil, errors.New("format must be 'csv' or 'tsv'")
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse input: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// resolveImportColumns maps each field name to a column index
+func resolveImportColumns(cols WordImportColumns, header []string) (map[string]int, error) {
+	requested := map[string]string{
+		"targetText": cols.TargetText,
+		"sourceText": cols.SourceText,
+		"imageUrl":   cols.ImageURL,
+		"audioUrl":   cols.AudioURL,
+	}
+
+	headerIndex := make(map[string]int, len(header))
+	for i, name := range header {
+		headerIndex[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	anyRequested := false
+	for _, spec := range requested {
+		if spec != "" {
+			anyRequested = true
+		}
+	}
+
+	columns := make(map[string]int)
+	for field, spec := range requested {
+		if spec != "" {
+			if idx, err := strconv.Atoi(spec); err == nil {
+				if idx < 0 {
+					return nil, fmt.Errorf("invalid column index for %s", field)
+				}
+				columns[field] = idx
+				continue
+			}
+			idx, ok := headerIndex[strings.ToLower(strings.TrimSpace(spec))]
+			if !ok {
+				return nil, fmt.Errorf("column %q for %s not found in header", spec, field)
+			}
+			columns[field] = idx
+			continue
+		}
+
+		if header != nil {
+			for _, alias := range importColumnAliases[field] {
+				if idx, ok := headerIndex[alias]; ok {
+					columns[field] = idx
+					break
+				}
+			}
+		}
+	}
+
+	// Positional defaults apply only when nothing was mapped explicitly or by header
+	if !anyRequested && len(columns) == 0 {
+		columns = map[string]int{"targetText": 0, "sourceText": 1, "imageUrl": 2, "audioUrl": 3}
+	}
+
+	if _, ok := columns["targetText"]; !ok {
+		return nil, errors.New("no column mapped to targetText")
+	}
+	return columns, nil
+}
+
+// buildImportWord validates one row's fields and builds the word to insert
+func buildImportWord(row int, targetText, sourceText, imageURL, audioURL string) (*models.Word, []WordImportError) {
+	var errs []WordImportError
+
+	if targetText == "" {
+		errs = append(errs, WordImportError{Row: row, Field: "targetText", Message: "target text is required"})
+	} else if len([]rune(targetText)) > maxImportTextLength {
+		errs = append(errs, WordImportError{Row: row, Field: "targetText", Message: fmt.Sprintf("target text is longer than %d characters", maxImportTextLength)})
+	}
+	if len([]rune(sourceText)) > maxImportTextLength {
+		errs = append(errs, WordImportError{Row: row, Field: "sourceText", Message: fmt.Sprintf("source text is longer than %d characters", maxImportTextLength)})
+	}
+	if imageURL != "" && !isValidMediaURL(imageURL) {
+		errs = append(errs, WordImportError{Row: row, Field: "imageUrl", Message: "image URL must be an /uploads/ path or http(s) URL"})
+	}
+	if audioURL != "" && !isValidMediaURL(audioURL) {
+		errs = append(errs, WordImportError{Row: row, Field: "audioUrl", Message: "audio URL must be an /uploads/ path or http(s) URL"})
+	}
+
+	if targetText == "" {
+		return nil, errs
+	}
+
+	word := &models.Word{
+		TargetText: targetText,
+		SourceText: sourceText,
+	}
+	if imageURL != "" {
+		word.ImageURL = &imageURL
+	}
+	if audioURL != "" {
+		word.AudioURL = &audioURL
+	}
+	return word, errs
+}
+
+func isValidMediaURL(u string) bool {
+	return strings.HasPrefix(u, "/uploads/") || strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://")
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseImportText(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		format string
+		want   [][]string
+		err    bool
+	}{
+		{
+			name: "csv detected",
+			text: "target,source\n蘋果,apple\n",
+			want: [][]string{{"target", "source"}, {"蘋果", "apple"}},
+		},
+		{
+			name: "tsv detected",
+			text: "蘋果\tapple, fruit\n香蕉\tbanana",
+			want: [][]string{{"蘋果", "apple, fruit"}, {"香蕉", "banana"}},
+		},
+		{
+			name:   "tsv forced",
+			text:   "a,b\tc",
+			format: "TSV",
+			want:   [][]string{{"a,b", "c"}},
+		},
+		{
+			name:   "csv forced despite tabs",
+			text:   "a\tb,c",
+			format: "csv",
+			want:   [][]string{{"a\tb", "c"}},
+		},
+		{
+			name: "byte order mark",
+			text: "\uFEFFword,meaning\n",
+			want: [][]string{{"word", "meaning"}},
+		},
+		{
+			name: "quoted fields",
+			text: "\"one, two\",\"say \"\"hi\"\"\"\n",
+			want: [][]string{{"one, two", `say "hi"`}},
+		},
+		{
+			name: "ragged rows",
+			text: "a\nb,c,d\n",
+			want: [][]string{{"a"}, {"b", "c", "d"}},
+		},
+		{name: "empty", text: " \n\t", err: true},
+		{name: "unknown format", text: "a,b", format: "xlsx", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseImportText(tt.text, tt.format)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("parseImportText() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseImportText() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseImportText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveImportColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		cols   WordImportColumns
+		header []string
+		want   map[string]int
+		err    bool
+	}{
+		{
+			name: "positional without header",
+			want: map[string]int{"targetText": 0, "sourceText": 1, "imageUrl": 2, "audioUrl": 3},
+		},
+		{
+			name:   "header aliases",
+			header: []string{"Meaning", " Word ", "Audio URL"},
+			want:   map[string]int{"targetText": 1, "sourceText": 0, "audioUrl": 2},
+		},
+		{
+			name:   "explicit names override aliases",
+			cols:   WordImportColumns{TargetText: "hanzi", SourceText: "english"},
+			header: []string{"word", "english", "hanzi"},
+			want:   map[string]int{"targetText": 2, "sourceText": 1},
+		},
+		{
+			name: "explicit indexes",
+			cols: WordImportColumns{TargetText: "2", AudioURL: "0"},
+			want: map[string]int{"targetText": 2, "audioUrl": 0},
+		},
+		{
+			name:   "unknown header falls back to positions",
+			header: []string{"foo", "bar"},
+			want:   map[string]int{"targetText": 0, "sourceText": 1, "imageUrl": 2, "audioUrl": 3},
+		},
+		{
+			name:   "header without target",
+			header: []string{"meaning", "image"},
+			err:    true,
+		},
+		{
+			name:   "missing named column",
+			cols:   WordImportColumns{TargetText: "hanzi"},
+			header: []string{"word"},
+			err:    true,
+		},
+		{
+			name: "negative index",
+			cols: WordImportColumns{TargetText: "-1"},
+			err:  true,
+		},
+		{
+			name: "only source mapped",
+			cols: WordImportColumns{SourceText: "1"},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveImportColumns(tt.cols, tt.header)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("resolveImportColumns() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveImportColumns() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveImportColumns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
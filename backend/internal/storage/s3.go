@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Storage keeps objects in a bucket of an S3-compatible service, such as
+// AWS S3 or MinIO. Objects are served from baseURL, which is either the API's
+// own media route or a public bucket or CDN address.
+type S3Storage struct {
+	client  *minio.Client
+	bucket  string
+	baseURL string
+}
+
+// NewS3Storage connects to endpoint (host[:port], no scheme) and checks that
+// the bucket exists
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey string, useSSL bool, baseURL string) (*S3Storage, error) {
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+		Region: region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+
+	exists, err := client.BucketExists(context.Background(), bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach S3 bucket %s: %w", bucket, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("S3 bucket %s does not exist", bucket)
+	}
+
+	return &S3Storage{client: client, bucket: bucket, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+func (s *S3Storage) Put(key string, r io.Reader, size int64, contentType string) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	_, err := s.client.PutObject(context.Background(), s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+func (s *S3Storage) Get(key string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	if err := CheckKey(key); err != nil {
+		return nil, nil, err
+	}
+	obj, err := s.client.GetObject(context.Background(), s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, nil, s3Error(err)
+	}
+	// GetObject is lazy; Stat makes the request and reports a missing key
+	stat, err := obj.Stat()
+	if err != nil {
+		obj.Close()
+		return nil, nil, s3Error(err)
+	}
+	return obj, objectInfo(stat), nil
+}
+
+func (s *S3Storage) Delete(key string) error {
+	if err := CheckKey(key); err != nil {
+		return err
+	}
+	return s3Error(s.client.RemoveObject(context.Background(), s.bucket, key, minio.RemoveObjectOptions{}))
+}
+
+func (s *S3Storage) Stat(key string) (*ObjectInfo, error) {
+	if err := CheckKey(key); err != nil {
+		return nil, err
+	}
+	stat, err := s.client.StatObject(context.Background(), s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, s3Error(err)
+	}
+	return objectInfo(stat), nil
+}
+
+func (s *S3Storage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+func (s *S3Storage) List(prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for obj := range s.client.ListObjects(context.Background(), s.bucket, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}) {
+		if obj.Err != nil {
+			return nil, s3Error(obj.Err)
+		}
+		objects = append(objects, *objectInfo(obj))
+	}
+	return objects, nil
+}
+
+func objectInfo(obj minio.ObjectInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Key:         obj.Key,
+		Size:        obj.Size,
+		ContentType: obj.ContentType,
+		ETag:        `"` + strings.Trim(obj.ETag, `"`) + `"`,
+		ModTime:     obj.LastModified,
+	}
+}
+
+// s3Error maps a missing key to ErrNotFound
+func s3Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	resp := minio.ToErrorResponse(err)
+	if resp.Code == "NoSuchKey" || resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	return err
+}
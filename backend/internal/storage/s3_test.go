@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// TestS3Storage runs against the S3-compatible service at S3_TEST_ENDPOINT
+// (host[:port]), such as a local MinIO:
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	S3_TEST_ENDPOINT=localhost:9000 go test ./internal/storage
+//
+// S3_TEST_ACCESS_KEY and S3_TEST_SECRET_KEY default to MinIO's, and
+// S3_TEST_BUCKET to learng-test, which is created if missing. Objects are
+// written under a prefix of their own and removed afterwards.
+func TestS3Storage(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set")
+	}
+	accessKey := envOr("S3_TEST_ACCESS_KEY", "minioadmin")
+	secretKey := envOr("S3_TEST_SECRET_KEY", "minioadmin")
+	bucket := envOr("S3_TEST_BUCKET", "learng-test")
+	useSSL := os.Getenv("S3_TEST_USE_SSL") == "true"
+
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
+		Secure: useSSL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	exists, err := client.BucketExists(ctx, bucket)
+	if err != nil {
+		t.Fatalf("failed to reach %s: %v", endpoint, err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := NewS3Storage(endpoint, "", bucket, accessKey, secretKey, useSSL, "/uploads")
+	if err != nil {
+		t.Fatalf("NewS3Storage() error = %v", err)
+	}
+	testStorage(t, store, fmt.Sprintf("test-%d/", time.Now().UnixNano()))
+
+	if _, err := NewS3Storage(endpoint, "", bucket+"-missing", accessKey, secretKey, useSSL, "/uploads"); err == nil {
+		t.Error("NewS3Storage() accepted a missing bucket")
+	}
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
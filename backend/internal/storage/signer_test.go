@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	signer := NewURLSigner(newTestLocalStorage(t, "/uploads"), "secret", time.Hour)
+
+	tests := []struct {
+		name   string
+		url    string
+		signed bool
+	}{
+		{name: "stored object", url: "/uploads/images/a/full.jpg", signed: true},
+		{name: "already signed", url: signer.Sign("/uploads/audio/b.wav"), signed: true},
+		{name: "external", url: "https://example.com/a.jpg"},
+		{name: "outside the store", url: "/static/a.jpg"},
+		{name: "escaping the store", url: "/uploads/../a.jpg"},
+		{name: "empty", url: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := signer.Sign(tt.url)
+			if !tt.signed {
+				if got != tt.url {
+					t.Errorf("Sign(%q) = %q, want it unchanged", tt.url, got)
+				}
+				return
+			}
+
+			base, rawQuery, _ := strings.Cut(got, "?")
+			if base != Unsigned(tt.url) {
+				t.Errorf("Sign(%q) = %q, want the plain URL signed", tt.url, got)
+			}
+			query, err := url.ParseQuery(rawQuery)
+			if err != nil || len(query["sig"]) != 1 || len(query["expires"]) != 1 {
+				t.Fatalf("Sign(%q) = %q, want one expires and one sig", tt.url, got)
+			}
+			key, _ := KeyFromURL(signer.store, base)
+			expiresAt, err := signer.Verify(key, query.Get("expires"), query.Get("sig"))
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if lifetime := time.Until(expiresAt); lifetime <= time.Hour || lifetime > 2*time.Hour {
+				t.Errorf("signed URL expires in %s, want between ttl and twice ttl", lifetime)
+			}
+			if again := signer.Sign(got); again != got {
+				t.Errorf("signing again gave %q, want %q", again, got)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	store := newTestLocalStorage(t, "/uploads")
+	signer := NewURLSigner(store, "secret", time.Hour)
+	const key = "images/a/full.jpg"
+	future := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	past := strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10)
+
+	tests := []struct {
+		name    string
+		key     string
+		expires string
+		sig     string
+		err     error
+	}{
+		{name: "valid", key: key, expires: future, sig: signer.signature(key, future)},
+		{name: "expired", key: key, expires: past, sig: signer.signature(key, past), err: ErrSignatureExpired},
+		{name: "other key", key: "images/b/full.jpg", expires: future, sig: signer.signature(key, future), err: ErrInvalidSignature},
+		{name: "extended expiry", key: key, expires: future + "0", sig: signer.signature(key, future), err: ErrInvalidSignature},
+		{name: "other secret", key: key, expires: future, sig: NewURLSigner(store, "other", time.Hour).signature(key, future), err: ErrInvalidSignature},
+		{name: "missing sig", key: key, expires: future, err: ErrInvalidSignature},
+		{name: "invalid expiry", key: key, expires: "tomorrow", sig: signer.signature(key, "tomorrow"), err: ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := signer.Verify(tt.key, tt.expires, tt.sig)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestUnsigned(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{url: "/uploads/a.jpg", want: "/uploads/a.jpg"},
+		{url: "/uploads/a.jpg?expires=1&sig=abc", want: "/uploads/a.jpg"},
+		{url: "/uploads/a.jpg?v=2&expires=1&sig=abc", want: "/uploads/a.jpg?v=2"},
+		{url: "/uploads/a.jpg?expires=1", want: "/uploads/a.jpg?expires=1"},
+		{url: "https://example.com/a.jpg?sig=x", want: "https://example.com/a.jpg"},
+		{url: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			if got := Unsigned(tt.url); got != tt.want {
+				t.Errorf("Unsigned(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeriveSigningKey(t *testing.T) {
+	key := DeriveSigningKey("secret")
+	if key == "" || key == "secret" {
+		t.Fatalf("DeriveSigningKey() = %q, want a key other than the secret", key)
+	}
+	if again := DeriveSigningKey("secret"); again != key {
+		t.Errorf("DeriveSigningKey() = %q then %q, want the same key", key, again)
+	}
+	if other := DeriveSigningKey("other"); other == key {
+		t.Errorf("DeriveSigningKey() gave %q for two secrets", key)
+	}
+}
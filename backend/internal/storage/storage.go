@@ -0,0 +1,57 @@
+// Package storage keeps uploaded media in a key-value object store. Keys are
+// slash-separated paths such as "images/<id>/full.jpg"; each backend maps a
+// key to the public URL the file is served from.
+package storage
+
+import (
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ETag        string
+	ModTime     time.Time
+}
+
+// Storage stores media objects by key
+type Storage interface {
+	Put(key string, r io.Reader, size int64, contentType string) error
+	// Get opens an object for reading; the reader can seek, for range requests
+	Get(key string) (io.ReadSeekCloser, *ObjectInfo, error)
+	Delete(key string) error
+	Stat(key string) (*ObjectInfo, error)
+	URL(key string) string
+	// List returns every object whose key starts with prefix
+	List(prefix string) ([]ObjectInfo, error)
+}
+
+// KeyFromURL reverses Storage.URL, reporting whether url is served by s
+func KeyFromURL(s Storage, url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, s.URL(""))
+	if !ok || CheckKey(key) != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// CheckKey rejects keys that are empty, absolute, or not in clean form, so a
+// key can never point outside the store
+func CheckKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key ||
+		key == "." || key == ".." || strings.HasPrefix(key, "../") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	return nil
+}
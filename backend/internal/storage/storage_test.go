@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestCheckKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{key: "images/abc/full.jpg", valid: true},
+		{key: "audio/abc.wav", valid: true},
+		{key: "file", valid: true},
+		{key: "..file", valid: true},
+		{key: ""},
+		{key: "/etc/passwd"},
+		{key: ".."},
+		{key: "../secret"},
+		{key: "images/../../secret"},
+		{key: "images/./a.jpg"},
+		{key: "images//a.jpg"},
+		{key: "images/"},
+		{key: "."},
+		{key: `images\..\secret`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := CheckKey(tt.key)
+			if tt.valid && err != nil {
+				t.Errorf("CheckKey(%q) = %v, want nil", tt.key, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("CheckKey(%q) = %v, want ErrInvalidKey", tt.key, err)
+			}
+		})
+	}
+}
+
+func TestKeyFromURL(t *testing.T) {
+	store := newTestLocalStorage(t, "/uploads/")
+
+	tests := []struct {
+		url string
+		key string
+		ok  bool
+	}{
+		{url: "/uploads/images/abc/full.jpg", key: "images/abc/full.jpg", ok: true},
+		{url: "/uploads/audio/abc.wav", key: "audio/abc.wav", ok: true},
+		{url: "/uploads/"},
+		{url: "/uploads"},
+		{url: "/uploads/../config.env"},
+		{url: "/uploads//etc/passwd"},
+		{url: "/static/logo.png"},
+		{url: "https://cdn.example.com/uploads/a.jpg"},
+		{url: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			key, ok := KeyFromURL(store, tt.url)
+			if key != tt.key || ok != tt.ok {
+				t.Errorf("KeyFromURL(%q) = %q, %v, want %q, %v", tt.url, key, ok, tt.key, tt.ok)
+			}
+			if ok && store.URL(key) != tt.url {
+				t.Errorf("URL(%q) = %q, want %q", key, store.URL(key), tt.url)
+			}
+		})
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	testStorage(t, newTestLocalStorage(t, "/uploads"), "test/")
+}
+
+func newTestLocalStorage(t *testing.T, baseURL string) *LocalStorage {
+	t.Helper()
+	store, err := NewLocalStorage(t.TempDir(), baseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+// testStorage exercises the Storage contract on store, with keys under prefix
+func testStorage(t *testing.T, store Storage, prefix string) {
+	content := []byte("\x89PNG not really an image")
+	keys := []string{prefix + "images/a/full.png", prefix + "images/a/card.png", prefix + "audio/b.png"}
+	for _, key := range keys {
+		if err := store.Put(key, bytes.NewReader(content), int64(len(content)), "image/png"); err != nil {
+			t.Fatalf("Put(%q) error = %v", key, err)
+		}
+	}
+	t.Cleanup(func() {
+		for _, key := range keys {
+			store.Delete(key)
+		}
+	})
+
+	t.Run("stat", func(t *testing.T) {
+		info, err := store.Stat(keys[0])
+		if err != nil {
+			t.Fatalf("Stat() error = %v", err)
+		}
+		if info.Key != keys[0] || info.Size != int64(len(content)) || info.ContentType != "image/png" {
+			t.Errorf("Stat() = %+v", info)
+		}
+		if info.ETag == "" || info.ModTime.IsZero() {
+			t.Errorf("Stat() has no ETag or ModTime: %+v", info)
+		}
+	})
+
+	t.Run("get", func(t *testing.T) {
+		rs, info, err := store.Get(keys[0])
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		defer rs.Close()
+		if info.Size != int64(len(content)) {
+			t.Errorf("Get() size = %d, want %d", info.Size, len(content))
+		}
+		if _, err := rs.Seek(5, io.SeekStart); err != nil {
+			t.Fatalf("Seek() error = %v", err)
+		}
+		got, err := io.ReadAll(rs)
+		if err != nil {
+			t.Fatalf("read error = %v", err)
+		}
+		if !bytes.Equal(got, content[5:]) {
+			t.Errorf("read %q after seeking, want %q", got, content[5:])
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		objects, err := store.List(prefix + "images/")
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		var got []string
+		for _, obj := range objects {
+			got = append(got, obj.Key)
+		}
+		sort.Strings(got)
+		want := []string{prefix + "images/a/card.png", prefix + "images/a/full.png"}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("List() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		key := keys[2]
+		if err := store.Delete(key); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := store.Stat(key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Stat() after Delete() error = %v, want ErrNotFound", err)
+		}
+		if _, _, err := store.Get(key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("invalid keys", func(t *testing.T) {
+		for _, key := range []string{"", "/abs.png", "../escape.png", prefix + "../escape.png"} {
+			if err := store.Put(key, bytes.NewReader(content), int64(len(content)), "image/png"); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+			}
+			if _, _, err := store.Get(key); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("Get(%q) error = %v, want ErrInvalidKey", key, err)
+			}
+		}
+	})
+}
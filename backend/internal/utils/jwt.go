@@ -10,15 +10,18 @@ type JWTClaims struct {
 	UserID string `json:"userId"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// SessionID ties the access token to a server-side session so it can be revoked
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
 // GenerateToken creates a new JWT token for a user
-func GenerateToken(userID, role, secret string, duration time.Duration) (string, error) {
+func GenerateToken(userID, role, sessionID, secret string, duration time.Duration) (string, error) {
 	claims := &JWTClaims{
-		UserID: userID,
-		Email:  "", // Email not included in token for security
-		Role:   role,
+		UserID:    userID,
+		Email:     "", // Email not included in token for security
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -0,0 +1,17 @@
+package utils
+
+import "fmt"
+
+// FormatSize renders a byte count for messages, e.g. "5MB", "1.5MB", "512KB"
+func FormatSize(bytes int64) string {
+	const kb, mb = 1024, 1024 * 1024
+	switch {
+	case bytes >= mb && bytes%mb == 0:
+		return fmt.Sprintf("%dMB", bytes/mb)
+	case bytes >= mb:
+		return fmt.Sprintf("%.1fMB", float64(bytes)/mb)
+	case bytes >= kb:
+		return fmt.Sprintf("%dKB", bytes/kb)
+	}
+	return fmt.Sprintf("%dB", bytes)
+}
@@ -1,8 +1,13 @@
 #!/bin/bash
 
 # Test Authentication Endpoints
+#
+# Start the server with BOOTSTRAP_ADMIN_EMAIL/BOOTSTRAP_ADMIN_PASSWORD matching
+# ADMIN_EMAIL/ADMIN_PASSWORD below on an empty database.
 
 BASE_URL="http://localhost:8080/api/v1"
+ADMIN_EMAIL="${ADMIN_EMAIL:-admin@learng.com}"
+ADMIN_PASSWORD="${ADMIN_PASSWORD:-admin123}"
 
 echo "🧪 Testing Authentication Endpoints"
 echo "===================================="
@@ -15,22 +20,20 @@ response=$(curl -s http://localhost:8080/health)
 echo "   Response: $response"
 echo ""
 
-# Test 2: Register Admin User
-echo "2. Register Admin User"
+# Test 2: Register Admin User without invite (should fail)
+echo "2. Register Admin User Without Invite (should fail)"
 echo "   POST $BASE_URL/auth/register"
 register_response=$(curl -s -X POST \
   -H "Content-Type: application/json" \
   -d '{
-    "email": "admin@learng.com",
+    "email": "rogue-admin@learng.com",
     "password": "admin123",
-    "displayName": "Admin User",
+    "displayName": "Rogue Admin",
     "role": "admin"
   }' \
   $BASE_URL/auth/register)
 
 echo "   Response: $register_response"
-admin_token=$(echo $register_response | jq -r '.token // empty')
-echo "   Token: $admin_token"
 echo ""
 
 # Test 3: Register Learner User
@@ -56,10 +59,10 @@ echo "   POST $BASE_URL/auth/register"
 duplicate_response=$(curl -s -X POST \
   -H "Content-Type: application/json" \
   -d '{
-    "email": "admin@learng.com",
+    "email": "learner@learng.com",
     "password": "password123",
-    "displayName": "Duplicate Admin",
-    "role": "admin"
+    "displayName": "Duplicate Learner",
+    "role": "learner"
   }' \
   $BASE_URL/auth/register)
 
@@ -67,18 +70,19 @@ echo "   Response: $duplicate_response"
 echo ""
 
 # Test 5: Login with correct credentials
-echo "5. Login with Correct Credentials"
+echo "5. Login as Bootstrap Admin"
 echo "   POST $BASE_URL/auth/login"
 login_response=$(curl -s -X POST \
   -H "Content-Type: application/json" \
-  -d '{
-    "email": "admin@learng.com",
-    "password": "admin123"
-  }' \
+  -d "{
+    \"email\": \"$ADMIN_EMAIL\",
+    \"password\": \"$ADMIN_PASSWORD\"
+  }" \
   $BASE_URL/auth/login)
 
 echo "   Response: $login_response"
 login_token=$(echo $login_response | jq -r '.token // empty')
+admin_token=$login_token
 echo ""
 
 # Test 6: Login with wrong password (should fail)
@@ -86,10 +90,10 @@ echo "6. Login with Wrong Password (should fail)"
 echo "   POST $BASE_URL/auth/login"
 wrong_pass_response=$(curl -s -X POST \
   -H "Content-Type: application/json" \
-  -d '{
-    "email": "admin@learng.com",
-    "password": "wrongpassword"
-  }' \
+  -d "{
+    \"email\": \"$ADMIN_EMAIL\",
+    \"password\": \"wrongpassword\"
+  }" \
   $BASE_URL/auth/login)
 
 echo "   Response: $wrong_pass_response"
@@ -122,7 +126,7 @@ invalid_email_response=$(curl -s -X POST \
     "email": "not-an-email",
     "password": "password123",
     "displayName": "Invalid Email",
-    "role": "admin"
+    "role": "learner"
   }' \
   $BASE_URL/auth/register)
 
@@ -138,7 +142,7 @@ weak_pass_response=$(curl -s -X POST \
     "email": "weak@learng.com",
     "password": "short",
     "displayName": "Weak Password",
-    "role": "admin"
+    "role": "learner"
   }' \
   $BASE_URL/auth/register)
 
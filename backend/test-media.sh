@@ -4,6 +4,9 @@
 # Tests image and audio upload functionality
 
 BASE_URL="http://localhost:8080/api/v1"
+# Uploads require an admin; start the server with a matching bootstrap admin
+ADMIN_EMAIL="${ADMIN_EMAIL:-admin@learng.com}"
+ADMIN_PASSWORD="${ADMIN_PASSWORD:-admin123}"
 TEMP_DIR="/tmp/learng-media-test"
 
 # Colors for output
@@ -39,11 +42,18 @@ create_test_image() {
     echo "iVBORw0KGgoAAAANSUhEUgAAAAEAAAABCAYAAAAfFcSJAAAADUlEQVR42mNk+M9QDwADhgGAWjR9awAAAABJRU5ErkJggg==" | base64 -d > "$filename"
 }
 
-# Function to create test audio file (minimal WAV)
+# Function to create test audio file: half a second of a 500Hz square wave as
+# 8kHz 16-bit mono WAV, since silent clips are rejected
 create_test_audio() {
     local filename="$1"
-    # Create a minimal WAV file header
-    printf "RIFF\x24\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x44\xAC\x00\x00\x88\x58\x01\x00\x02\x00\x10\x00data\x00\x00\x00\x00" > "$filename"
+    # Header for 8000 bytes of samples
+    printf "RIFF\x64\x1F\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00\x40\x1F\x00\x00\x80\x3E\x00\x00\x02\x00\x10\x00data\x40\x1F\x00\x00" > "$filename"
+    # 250 periods of 8 samples at +8000 and 8 at -8000
+    local high="\x40\x1F\x40\x1F\x40\x1F\x40\x1F\x40\x1F\x40\x1F\x40\x1F\x40\x1F"
+    local low="\xC0\xE0\xC0\xE0\xC0\xE0\xC0\xE0\xC0\xE0\xC0\xE0\xC0\xE0\xC0\xE0"
+    for _ in $(seq 250); do
+        printf "$high$low"
+    done >> "$filename"
 }
 
 echo "================================================"
@@ -51,37 +61,22 @@ echo "  Media Upload Endpoints Test Suite"
 echo "================================================"
 echo ""
 
-# Step 1: Register a test user
+# Step 1: Log in as admin
 echo "1. Setting up test user..."
-REGISTER_RESPONSE=$(curl -s -X POST "$BASE_URL/auth/register" \
+LOGIN_RESPONSE=$(curl -s -X POST "$BASE_URL/auth/login" \
   -H "Content-Type: application/json" \
-  -d '{
-    "email": "mediatest@example.com",
-    "password": "testpass123",
-    "displayName": "Media Test User",
-    "role": "admin"
-  }')
+  -d "{
+    \"email\": \"$ADMIN_EMAIL\",
+    \"password\": \"$ADMIN_PASSWORD\"
+  }")
 
-if echo "$REGISTER_RESPONSE" | grep -q '"token"'; then
-    print_status 0 "User registration"
-    TOKEN=$(echo "$REGISTER_RESPONSE" | grep -o '"token":"[^"]*"' | cut -d'"' -f4)
+if echo "$LOGIN_RESPONSE" | grep -q '"token"'; then
+    print_status 0 "Admin login"
+    TOKEN=$(echo "$LOGIN_RESPONSE" | grep -o '"token":"[^"]*"' | cut -d'"' -f4)
 else
-    # Try to login if user already exists
-    LOGIN_RESPONSE=$(curl -s -X POST "$BASE_URL/auth/login" \
-      -H "Content-Type: application/json" \
-      -d '{
-        "email": "mediatest@example.com",
-        "password": "testpass123"
-      }')
-    
-    if echo "$LOGIN_RESPONSE" | grep -q '"token"'; then
-        print_status 0 "User login (existing user)"
-        TOKEN=$(echo "$LOGIN_RESPONSE" | grep -o '"token":"[^"]*"' | cut -d'"' -f4)
-    else
-        print_status 1 "Authentication failed"
-        echo "Response: $LOGIN_RESPONSE"
-        exit 1
-    fi
+    print_status 1 "Authentication failed"
+    echo "Response: $LOGIN_RESPONSE"
+    exit 1
 fi
 
 echo ""
@@ -94,7 +89,8 @@ IMAGE_RESPONSE=$(curl -s -X POST "$BASE_URL/media/upload/image" \
   -F "file=@$TEMP_DIR/test.png")
 
 if echo "$IMAGE_RESPONSE" | grep -q '"url"'; then
-    IMAGE_URL=$(echo "$IMAGE_RESPONSE" | grep -o '"url":"[^"]*"' | cut -d'"' -f4)
+    # The first url is the full variant; the variants follow with their own
+    IMAGE_URL=$(echo "$IMAGE_RESPONSE" | grep -o '"url":"[^"]*"' | cut -d'"' -f4 | head -1 | sed 's/\\u0026/\&/g')
     print_status 0 "Image upload (PNG) - URL: $IMAGE_URL"
 else
     print_status 1 "Image upload (PNG)"
@@ -105,8 +101,8 @@ echo ""
 
 # Test 3: Upload JPEG Image
 echo "3. Testing image upload (JPEG)..."
-# Create JPEG test file (minimal JPEG header)
-printf "\xFF\xD8\xFF\xE0\x00\x10JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00\xFF\xD9" > "$TEMP_DIR/test.jpg"
+# Create a 1x1 JPEG test file; uploads are decoded, so a bare header is rejected
+echo "/9j/2wCEABALDA4MChAODQ4SERATGCgaGBYWGDEjJR0oOjM9PDkzODdASFxOQERXRTc4UG1RV19iZ2hnPk1xeXBkeFxlZ2MBERISGBUYLxoaL2NCOEJjY2NjY2NjY2NjY2NjY2NjY2NjY2NjY2NjY2NjY2NjY2NjY2NjY2NjY2NjY2NjY2NjY//AABEIAAEAAQMBIgACEQEDEQH/xAGiAAABBQEBAQEBAQAAAAAAAAAAAQIDBAUGBwgJCgsQAAIBAwMCBAMFBQQEAAABfQECAwAEEQUSITFBBhNRYQcicRQygZGhCCNCscEVUtHwJDNicoIJChYXGBkaJSYnKCkqNDU2Nzg5OkNERUZHSElKU1RVVldYWVpjZGVmZ2hpanN0dXZ3eHl6g4SFhoeIiYqSk5SVlpeYmZqio6Slpqeoqaqys7S1tre4ubrCw8TFxsfIycrS09TV1tfY2drh4uPk5ebn6Onq8fLz9PX29/j5+gEAAwEBAQEBAQEBAQAAAAAAAAECAwQFBgcICQoLEQACAQIEBAMEBwUEBAABAncAAQIDEQQFITEGEkFRB2FxEyIygQgUQpGhscEJIzNS8BVictEKFiQ04SXxFxgZGiYnKCkqNTY3ODk6Q0RFRkdISUpTVFVWV1hZWmNkZWZnaGlqc3R1dnd4eXqCg4SFhoeIiYqSk5SVlpeYmZqio6Slpqeoqaqys7S1tre4ubrCw8TFxsfIycrS09TV1tfY2dri4+Tl5ufo6ery8/T19vf4+fr/2gAMAwEAAhEDEQA/AKVFFFch9Cf/2Q==" | base64 -d > "$TEMP_DIR/test.jpg"
 JPEG_RESPONSE=$(curl -s -X POST "$BASE_URL/media/upload/image" \
   -H "Authorization: Bearer $TOKEN" \
   -F "file=@$TEMP_DIR/test.jpg")
@@ -128,7 +124,7 @@ AUDIO_RESPONSE=$(curl -s -X POST "$BASE_URL/media/upload/audio" \
   -F "file=@$TEMP_DIR/test.wav")
 
 if echo "$AUDIO_RESPONSE" | grep -q '"url"'; then
-    AUDIO_URL=$(echo "$AUDIO_RESPONSE" | grep -o '"url":"[^"]*"' | cut -d'"' -f4)
+    AUDIO_URL=$(echo "$AUDIO_RESPONSE" | grep -o '"url":"[^"]*"' | cut -d'"' -f4 | sed 's/\\u0026/\&/g')
     print_status 0 "Audio upload (WAV) - URL: $AUDIO_URL"
 else
     print_status 1 "Audio upload (WAV)"
@@ -199,28 +195,40 @@ fi
 echo ""
 
 # Test 9: Verify uploaded files are accessible
+# Responses carry signed URLs that work without a token; the plain URL needs
+# the access token in the Authorization header
 echo "9. Testing uploaded files are accessible..."
-if [ -n "$IMAGE_URL" ]; then
-    HTTP_CODE=$(curl -s -o /dev/null -w "%{http_code}" "http://localhost:8080$IMAGE_URL")
+check_media_access() {
+    local label="$1" url="$2"
+    if [ -z "$url" ]; then
+        print_status 1 "$label accessible (no URL)"
+        return
+    fi
+
+    HTTP_CODE=$(curl -s -o /dev/null -w "%{http_code}" "http://localhost:8080$url")
     if [ "$HTTP_CODE" -eq 200 ]; then
-        print_status 0 "Image file accessible"
+        print_status 0 "$label accessible through signed URL"
     else
-        print_status 1 "Image file accessible (HTTP $HTTP_CODE)"
+        print_status 1 "$label accessible through signed URL (HTTP $HTTP_CODE)"
     fi
-else
-    print_status 1 "Image file accessible (no URL)"
-fi
 
-if [ -n "$AUDIO_URL" ]; then
-    HTTP_CODE=$(curl -s -o /dev/null -w "%{http_code}" "http://localhost:8080$AUDIO_URL")
+    HTTP_CODE=$(curl -s -o /dev/null -w "%{http_code}" "http://localhost:8080${url%%\?*}" \
+      -H "Authorization: Bearer $TOKEN")
     if [ "$HTTP_CODE" -eq 200 ]; then
-        print_status 0 "Audio file accessible"
+        print_status 0 "$label accessible with access token"
     else
-        print_status 1 "Audio file accessible (HTTP $HTTP_CODE)"
+        print_status 1 "$label accessible with access token (HTTP $HTTP_CODE)"
     fi
-else
-    print_status 1 "Audio file accessible (no URL)"
-fi
+
+    HTTP_CODE=$(curl -s -o /dev/null -w "%{http_code}" "http://localhost:8080${url%%\?*}")
+    if [ "$HTTP_CODE" -eq 401 ]; then
+        print_status 0 "$label refused without token or signature"
+    else
+        print_status 1 "$label refused without token or signature (HTTP $HTTP_CODE)"
+    fi
+}
+check_media_access "Image file" "$IMAGE_URL"
+check_media_access "Audio file" "$AUDIO_URL"
 
 echo ""
 
@@ -4,6 +4,9 @@
 # Tests Journey, Scenario, and Word CRUD operations
 
 BASE_URL="http://localhost:8080/api/v1"
+# Content routes require an admin; start the server with a matching bootstrap admin
+ADMIN_EMAIL="${ADMIN_EMAIL:-admin@learng.com}"
+ADMIN_PASSWORD="${ADMIN_PASSWORD:-admin123}"
 TOKEN=""
 JOURNEY_ID=""
 SCENARIO_ID=""
@@ -14,26 +17,24 @@ echo "Sprint 2 - Journey/Scenario/Word CRUD API Tests"
 echo "======================================"
 echo ""
 
-# Step 1: Register a test admin user
-echo "1. Registering test admin user..."
-REGISTER_RESPONSE=$(curl -s -X POST "$BASE_URL/auth/register" \
+# Step 1: Log in as the admin user
+echo "1. Logging in as admin user..."
+LOGIN_RESPONSE=$(curl -s -X POST "$BASE_URL/auth/login" \
   -H "Content-Type: application/json" \
-  -d '{
-    "email": "admin@test.com",
-    "password": "Test1234",
-    "displayName": "Test Admin",
-    "role": "admin"
-  }')
+  -d "{
+    \"email\": \"$ADMIN_EMAIL\",
+    \"password\": \"$ADMIN_PASSWORD\"
+  }")
 
-echo "Register Response: $REGISTER_RESPONSE"
-TOKEN=$(echo $REGISTER_RESPONSE | grep -o '"token":"[^"]*' | cut -d'"' -f4)
+echo "Login Response: $LOGIN_RESPONSE"
+TOKEN=$(echo $LOGIN_RESPONSE | grep -o '"token":"[^"]*' | cut -d'"' -f4)
 
 if [ -z "$TOKEN" ]; then
-  echo "❌ Failed to register user or get token"
+  echo "❌ Failed to log in or get token"
   exit 1
 fi
 
-echo "✅ User registered successfully"
+echo "✅ Logged in successfully"
 echo "Token: ${TOKEN:0:20}..."
 echo ""
 
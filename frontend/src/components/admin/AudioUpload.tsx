@@ -128,7 +128,7 @@ export const AudioUpload: React.FC<AudioUploadProps> = ({
         <div className="border border-gray-200 rounded-lg p-4 space-y-3">
           <audio
             ref={audioRef}
-            src={audioUrl.startsWith('/') ? `http://localhost:8080${audioUrl}` : audioUrl}
+            src={mediaService.previewUrl(audioUrl)}
             controls
             className="w-full"
           />
@@ -92,7 +92,7 @@ export const ImageUpload: React.FC<ImageUploadProps> = ({
       {preview ? (
         <div className="relative">
           <img
-            src={preview.startsWith('/') ? `http://localhost:8080${preview}` : preview}
+            src={mediaService.previewUrl(preview)}
             alt="Preview"
             className="w-full h-48 object-cover rounded-lg border border-gray-200"
           />
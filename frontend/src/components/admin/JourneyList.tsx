@@ -45,7 +45,9 @@ export const JourneyList: React.FC = () => {
       await journeyService.publishJourney(id);
       await loadJourneys();
     } catch (err: any) {
-      alert(err.response?.data?.error || 'Failed to publish journey');
+      const issues: { message: string }[] = err.response?.data?.report?.errors || [];
+      const details = issues.map((issue) => `- ${issue.message}`).join('\n');
+      alert([err.response?.data?.error || 'Failed to publish journey', details].filter(Boolean).join('\n\n'));
     }
   };
 
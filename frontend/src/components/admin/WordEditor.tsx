@@ -22,7 +22,6 @@ export const WordEditor: React.FC<WordEditorProps> = ({
     scenarioId: word?.scenarioId || scenarioId,
     targetText: word?.targetText || '',
     sourceText: word?.sourceText || '',
-    displayOrder: word?.displayOrder || 0,
     imageUrl: word?.imageUrl || '',
     audioUrl: word?.audioUrl || '',
   });
@@ -76,16 +75,6 @@ export const WordEditor: React.FC<WordEditorProps> = ({
             placeholder="e.g., apple"
             required
           />
-
-          <Input
-            label="Display Order"
-            type="number"
-            value={formData.displayOrder}
-            onChange={(e) =>
-              setFormData({ ...formData, displayOrder: parseInt(e.target.value) || 0 })
-            }
-            min={0}
-          />
         </div>
 
         <div className="space-y-4">
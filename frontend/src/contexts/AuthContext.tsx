@@ -7,7 +7,7 @@ interface AuthContextType {
   isAuthenticated: boolean;
   isLoading: boolean;
   login: (email: string, password: string) => Promise<void>;
-  register: (email: string, password: string, displayName: string, role: 'admin' | 'learner') => Promise<void>;
+  register: (email: string, password: string, displayName: string, role: 'admin' | 'learner', inviteCode?: string) => Promise<void>;
   logout: () => void;
 }
 
@@ -37,8 +37,8 @@ export const AuthProvider: React.FC<{ children: ReactNode }> = ({ children }) =>
     setUser(response.user);
   };
 
-  const register = async (email: string, password: string, displayName: string, role: 'admin' | 'learner') => {
-    const response = await authService.register({ email, password, displayName, role });
+  const register = async (email: string, password: string, displayName: string, role: 'admin' | 'learner', inviteCode?: string) => {
+    const response = await authService.register({ email, password, displayName, role, inviteCode });
     setUser(response.user);
   };
 
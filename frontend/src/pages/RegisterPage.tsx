@@ -12,6 +12,7 @@ export const RegisterPage: React.FC = () => {
     password: '',
     displayName: '',
     role: 'learner' as 'admin' | 'learner',
+    inviteCode: '',
   });
   const [error, setError] = useState('');
   const [isLoading, setIsLoading] = useState(false);
@@ -26,7 +27,8 @@ export const RegisterPage: React.FC = () => {
         formData.email,
         formData.password,
         formData.displayName,
-        formData.role
+        formData.role,
+        formData.inviteCode || undefined
       );
       navigate('/');
     } catch (err: any) {
@@ -109,6 +111,18 @@ export const RegisterPage: React.FC = () => {
                 Select "Admin" to manage content, or "Learner" to study
               </p>
             </div>
+
+            {formData.role === 'admin' && (
+              <Input
+                label="Invite Code"
+                type="text"
+                value={formData.inviteCode}
+                onChange={(e) => setFormData({ ...formData, inviteCode: e.target.value })}
+                required
+                placeholder="Code from an existing admin"
+                helperText="Admin accounts require an invite code"
+              />
+            )}
           </div>
 
           <Button type="submit" className="w-full" isLoading={isLoading}>
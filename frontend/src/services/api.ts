@@ -1,4 +1,4 @@
-import axios, { AxiosError } from 'axios';
+import axios, { AxiosError, InternalAxiosRequestConfig } from 'axios';
 
 // Create axios instance with default config
 const api = axios.create({
@@ -23,15 +23,62 @@ api.interceptors.request.use(
   }
 );
 
-// Response interceptor - handle errors globally
+const clearSession = () => {
+  localStorage.removeItem('authToken');
+  localStorage.removeItem('refreshToken');
+  localStorage.removeItem('user');
+  window.location.href = '/login';
+};
+
+// Shared so concurrent 401s trigger a single refresh; the server treats a
+// reused refresh token as theft and revokes the session
+let refreshPromise: Promise<string> | null = null;
+
+const refreshAccessToken = (): Promise<string> => {
+  if (!refreshPromise) {
+    const refreshToken = localStorage.getItem('refreshToken');
+    refreshPromise = axios
+      .post('/api/v1/auth/refresh', { refreshToken })
+      .then((response) => {
+        localStorage.setItem('authToken', response.data.token);
+        localStorage.setItem('refreshToken', response.data.refreshToken);
+        return response.data.token as string;
+      })
+      .finally(() => {
+        refreshPromise = null;
+      });
+  }
+  return refreshPromise;
+};
+
+// Response interceptor - refresh expired access tokens, handle errors globally
 api.interceptors.response.use(
   (response) => response,
-  (error: AxiosError) => {
-    if (error.response?.status === 401) {
+  async (error: AxiosError) => {
+    const original = error.config as (InternalAxiosRequestConfig & { _retry?: boolean }) | undefined;
+    // Credential endpoints report bad credentials with 401; don't refresh or redirect
+    const isAuthCall = /\/api\/v1\/auth\/(login|register|refresh)$/.test(original?.url ?? '');
+
+    if (error.response?.status === 401 && original && !original._retry && !isAuthCall) {
+      if (!localStorage.getItem('refreshToken')) {
+        clearSession();
+        return Promise.reject(error);
+      }
+
+      original._retry = true;
+      try {
+        const token = await refreshAccessToken();
+        original.headers.Authorization = `Bearer ${token}`;
+        return api(original);
+      } catch {
+        clearSession();
+        return Promise.reject(error);
+      }
+    }
+
+    if (error.response?.status === 401 && !isAuthCall) {
       // Unauthorized - clear token and redirect to login
-      localStorage.removeItem('authToken');
-      localStorage.removeItem('user');
-      window.location.href = '/login';
+      clearSession();
     }
     return Promise.reject(error);
   }
@@ -8,10 +8,11 @@ import {
 export const authService = {
   async login(credentials: LoginRequest): Promise<AuthResponse> {
     const response = await api.post<AuthResponse>('/api/v1/auth/login', credentials);
-    const { user, token } = response.data;
+    const { user, token, refreshToken } = response.data;
     
-    // Store token and user in localStorage
+    // Store tokens and user in localStorage
     localStorage.setItem('authToken', token);
+    localStorage.setItem('refreshToken', refreshToken);
     localStorage.setItem('user', JSON.stringify(user));
     
     return response.data;
@@ -19,17 +20,23 @@ export const authService = {
 
   async register(data: RegisterRequest): Promise<AuthResponse> {
     const response = await api.post<AuthResponse>('/api/v1/auth/register', data);
-    const { user, token } = response.data;
+    const { user, token, refreshToken } = response.data;
     
-    // Store token and user in localStorage
+    // Store tokens and user in localStorage
     localStorage.setItem('authToken', token);
+    localStorage.setItem('refreshToken', refreshToken);
     localStorage.setItem('user', JSON.stringify(user));
     
     return response.data;
   },
 
   logout(): void {
+    // Revoke the server-side session; local state is cleared regardless
+    if (localStorage.getItem('authToken')) {
+      api.post('/api/v1/auth/logout').catch(() => undefined);
+    }
     localStorage.removeItem('authToken');
+    localStorage.removeItem('refreshToken');
     localStorage.removeItem('user');
   },
 
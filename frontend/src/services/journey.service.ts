@@ -34,7 +34,18 @@ export const journeyService = {
     await api.delete(`/api/v1/journeys/${id}`);
   },
 
-  async publishJourney(id: string): Promise<Journey> {
-    return this.updateJourney(id, { status: 'published' } as Partial<CreateJourneyRequest>);
+  async publishJourney(id: string, publishAt?: string): Promise<Journey> {
+    const response = await api.post<Journey>(`/api/v1/journeys/${id}/publish`, { publishAt });
+    return response.data;
+  },
+
+  async unpublishJourney(id: string): Promise<Journey> {
+    const response = await api.post<Journey>(`/api/v1/journeys/${id}/unpublish`);
+    return response.data;
+  },
+
+  async archiveJourney(id: string): Promise<Journey> {
+    const response = await api.post<Journey>(`/api/v1/journeys/${id}/archive`);
+    return response.data;
   },
 };
@@ -39,4 +39,10 @@ export const mediaService = {
     );
     return response.data;
   },
+
+  // Media URLs in API responses are signed, so image and audio elements can
+  // load them without the access token
+  previewUrl(url: string): string {
+    return url.startsWith('/') ? `http://localhost:8080${url}` : url;
+  },
 };
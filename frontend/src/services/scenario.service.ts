@@ -12,7 +12,7 @@ export const scenarioService = {
     return response.data;
   },
 
-  async updateScenario(id: string, data: Partial<CreateScenarioRequest>): Promise<Scenario> {
+  async updateScenario(id: string, data: Partial<Pick<CreateScenarioRequest, 'title' | 'description'>>): Promise<Scenario> {
     const response = await api.put<Scenario>(`/api/v1/scenarios/${id}`, data);
     return response.data;
   },
@@ -20,4 +20,9 @@ export const scenarioService = {
   async deleteScenario(id: string): Promise<void> {
     await api.delete(`/api/v1/scenarios/${id}`);
   },
+
+  async reorderScenarios(journeyId: string, ids: string[]): Promise<Scenario[]> {
+    const response = await api.put<Scenario[]>(`/api/v1/journeys/${journeyId}/scenarios/order`, { ids });
+    return response.data;
+  },
 };
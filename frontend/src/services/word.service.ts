@@ -1,5 +1,5 @@
 import api from './api';
-import { Word, CreateWordRequest, UpdateWordRequest } from '@/types/api.types';
+import { Word, CreateWordRequest, UpdateWordRequest, GenerateMediaRequest } from '@/types/api.types';
 
 export const wordService = {
   async getWordById(id: string): Promise<Word> {
@@ -20,4 +20,14 @@ export const wordService = {
   async deleteWord(id: string): Promise<void> {
     await api.delete(`/api/v1/words/${id}`);
   },
+
+  async generateMedia(id: string, data: GenerateMediaRequest): Promise<Word> {
+    const response = await api.post<Word>(`/api/v1/words/${id}/generate`, data);
+    return response.data;
+  },
+
+  async reorderWords(scenarioId: string, ids: string[]): Promise<Word[]> {
+    const response = await api.put<Word[]>(`/api/v1/scenarios/${scenarioId}/words/order`, { ids });
+    return response.data;
+  },
 };
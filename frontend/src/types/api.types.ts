@@ -18,6 +18,8 @@ export interface User {
 export interface AuthResponse {
   user: User;
   token: string;
+  refreshToken: string;
+  expiresIn: number;
 }
 
 export interface LoginRequest {
@@ -30,6 +32,7 @@ export interface RegisterRequest {
   password: string;
   displayName: string;
   role: 'admin' | 'learner';
+  inviteCode?: string;
 }
 
 // Journey Types
@@ -39,7 +42,10 @@ export interface Journey {
   description: string;
   sourceLanguage: string;
   targetLanguage: string;
-  status: 'draft' | 'published' | 'archived';
+  status: 'draft' | 'scheduled' | 'published' | 'archived';
+  publishAt?: string | null;
+  publishedAt?: string | null;
+  liveVersionId?: string | null;
   createdBy: string;
   createdAt: string;
   updatedAt: string;
@@ -79,7 +85,7 @@ export interface CreateScenarioRequest {
   journeyId: string;
   title: string;
   description: string;
-  displayOrder: number;
+  displayOrder?: number;
 }
 
 // Word Types
@@ -90,7 +96,10 @@ export interface Word {
   sourceText: string;
   displayOrder: number;
   imageUrl: string | null;
+  imageSet?: ImageSet;
   audioUrl: string | null;
+  imageAssetId: string | null;
+  audioAssetId: string | null;
   generationMethod: 'manual' | 'ai_image' | 'ai_audio' | 'ai_both';
   createdAt: string;
   updatedAt: string;
@@ -101,27 +110,79 @@ export interface CreateWordRequest {
   scenarioId: string;
   targetText: string;
   sourceText: string;
-  displayOrder: number;
+  displayOrder?: number;
   imageUrl?: string;
   audioUrl?: string;
+  imageAssetId?: string;
+  audioAssetId?: string;
   generationMethod?: 'manual' | 'ai_image' | 'ai_audio' | 'ai_both';
 }
 
 export interface UpdateWordRequest {
   targetText?: string;
   sourceText?: string;
-  displayOrder?: number;
   imageUrl?: string;
   audioUrl?: string;
+  imageAssetId?: string;
+  audioAssetId?: string;
+}
+
+export interface GenerateMediaRequest {
+  image?: boolean;
+  audio?: boolean;
 }
 
 // Media Types
+export interface ImageSource {
+  webp: string;
+  original: string;
+}
+
+export interface ImageSet {
+  thumbnail: ImageSource;
+  card: ImageSource;
+  full: ImageSource;
+}
+
+export interface ImageVariant {
+  name: 'thumbnail' | 'card' | 'full';
+  url: string;
+  mimeType: string;
+  width: number;
+  height: number;
+  size: number;
+}
+
 export interface MediaUploadResponse {
+  assetId: string;
+  deduplicated: boolean;
   url: string;
   filename: string;
   size: number;
   mimeType: string;
+  width?: number;
+  height?: number;
+  imageSet?: ImageSet;
+  variants?: ImageVariant[];
+  duration?: number;
+}
+
+export interface MediaAsset {
+  id: string;
+  hash: string;
+  kind: 'image' | 'audio';
+  mimeType: string;
+  url: string;
+  size: number;
+  width?: number;
+  height?: number;
   duration?: number;
+  variants?: ImageVariant[];
+  imageSet?: ImageSet;
+  uploadedBy: string;
+  createdAt: string;
+  lastUsedAt: string;
+  usageCount: number;
 }
 
 // Quiz Types